@@ -3,11 +3,11 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/northvolt/go-atecc/pkg/atecc"
@@ -19,15 +19,20 @@ const (
 	inputDefault = "default"
 	inputHex     = "hex"
 	inputJSON    = "json"
+	inputC       = "c"
 	inputDevice  = "device"
 
+	// inputTemplate is the prefix of an embedded template, e.g. template:pkcs11.
+	inputTemplate = "template:"
+
 	outputGo     = "go"
 	outputHex    = "hex"
 	outputJSON   = "json"
+	outputC      = "c"
 	outputDevice = "device"
 )
 
-var allOutputs = []string{outputHex, outputJSON, outputDevice}
+var allOutputs = []string{outputGo, outputC, outputHex, outputJSON, outputDevice}
 
 type confConfig struct {
 	rootConfig *rootConfig
@@ -127,17 +132,12 @@ func useProvisionConfig(
 		return nil
 	case outputGo:
 		conf := provisionBytes[ateccconf.PermanentOffset608:]
-
-		var src strings.Builder
-		src.WriteString("[...]byte{")
-		for i, b := range conf {
-			if (i % 8) == 0 {
-				src.WriteString("\n ")
-			}
-			fmt.Fprintf(&src, " 0x%02x,", b)
-		}
-		src.WriteString("\n}")
-		fmt.Fprintln(w, src.String())
+		fmt.Fprintln(w, formatByteArray(conf, "[...]byte{", "}"))
+		return nil
+	case outputC:
+		// cryptoauthlib expects the full config zone, incl the first 16 bytes
+		decl := fmt.Sprintf("const uint8_t ecc608_configdata[%d] = {", len(provisionBytes))
+		fmt.Fprintln(w, formatByteArray(provisionBytes, decl, "};"))
 		return nil
 	case outputJSON:
 		return writeJSON(w, provisionConf)
@@ -162,9 +162,6 @@ To continue with this operation, re-run with -dry=false.`)
 		}
 
 		if !di.IsConfigZoneLocked {
-			// TODO: create manifest
-			// https://github.com/MicrochipTech/cryptoauth_trustplatform_designsuite/blob/master/docs/TrustPlatform_manifest_file_format_2019-09-26_A.pdf
-			// https://github.com/MicrochipTech/cryptoauth_trustplatform_designsuite/tree/master/assets/python/manifest_helper
 			if err := d.WriteConfigZone(ctx, provisionBytes); err != nil {
 				return err
 			}
@@ -183,7 +180,13 @@ To continue with this operation, re-run with -dry=false.`)
 				return fmt.Errorf("configuration read from device does not match")
 			}
 
-			if err := d.LockConfigZone(ctx); err != nil {
+			// Lock what was intended, with the factory header of the device,
+			// so that the device refuses to lock a corrupted config zone
+			expected := append(
+				currentBytes[:ateccconf.PermanentOffset608:ateccconf.PermanentOffset608],
+				provisionBytes[ateccconf.PermanentOffset608:]...,
+			)
+			if err := d.LockConfigZoneCRC(ctx, expected); err != nil {
 				return err
 			}
 		} else {
@@ -208,6 +211,21 @@ To continue with this operation, re-run with -dry=false.`)
 	}
 }
 
+// formatByteArray formats the bytes as the elements of an array literal.
+func formatByteArray(b []byte, open string, close string) string {
+	var src strings.Builder
+	src.WriteString(open)
+	for i, v := range b {
+		if (i % 8) == 0 {
+			src.WriteString("\n ")
+		}
+		fmt.Fprintf(&src, " 0x%02x,", v)
+	}
+	src.WriteString("\n")
+	src.WriteString(close)
+	return src.String()
+}
+
 func keyGen(ctx context.Context, w io.Writer, dry bool, d *atecc.Dev) error {
 	// Read latest config zone after writes and all
 	configZone, err := d.ReadConfigZone(ctx)
@@ -272,26 +290,32 @@ func keyGen(ctx context.Context, w io.Writer, dry bool, d *atecc.Dev) error {
 
 // createProvisionConfig creates a configuration for provisioning a device.
 func createProvisionConfig(provision string, r io.Reader, deviceConf ateccconf.Config608) (*ateccconf.Config608, error) {
+	if name, ok := strings.CutPrefix(provision, inputTemplate); ok {
+		t, err := ateccconf.Template(name)
+		if err != nil {
+			return nil, err
+		}
+		return t.Config()
+	}
+
 	switch provision {
 	case inputDefault:
 		return ateccconf.DefaultConfig608(), nil
-	case inputHex:
+	case inputHex, inputC:
 		in, err := io.ReadAll(r)
 		if err != nil {
 			return nil, err
 		}
 
-		// Remove any whitespace incl newline
-		s := strings.Join(strings.Fields(string(in)), "")
-
-		b, err := hex.DecodeString(s)
+		parse := map[string]func([]byte) ([]byte, error){
+			inputHex: ateccconf.ParseHex,
+			inputC:   ateccconf.ParseCArray,
+		}[provision]
+		b, err := parse(in)
 		if err != nil {
 			return nil, err
 		}
-
-		var conf ateccconf.Config608
-		err = ateccconf.UnmarshalPartial(b, ateccconf.PermanentOffset608, &conf)
-		return &conf, err
+		return ateccconf.UnmarshalConfig608(b)
 	case inputJSON:
 		var conf ateccconf.Config608
 		err := json.NewDecoder(r).Decode(&conf)
@@ -299,8 +323,65 @@ func createProvisionConfig(provision string, r io.Reader, deviceConf ateccconf.C
 	case inputDevice:
 		return &deviceConf, nil
 	default:
-		return nil, fmt.Errorf("valid config sources are default, device, hex, json")
+		return nil, fmt.Errorf("valid config sources are default, device, hex, json, c, template:<name>")
+	}
+}
+
+// loadConfig reads a configuration from the input, only connecting to the
+// device when it is used as input.
+func loadConfig(ctx context.Context, rootConfig *rootConfig, input string, r io.Reader) (*ateccconf.Config608, error) {
+	var conf ateccconf.Config608
+	if input == inputDevice {
+		d, bus, err := newATECC(ctx, rootConfig)
+		if err != nil {
+			return nil, err
+		}
+		defer bus.Close()
+
+		configZone, err := d.ReadConfigZone(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := ateccconf.Unmarshal(configZone, &conf); err != nil {
+			return nil, err
+		}
+	}
+	return createProvisionConfig(input, r, conf)
+}
+
+// loadConfigSource reads a configuration from a source given as argument.
+//
+// The source is either one of the inputs default, device and template:<name>,
+// - for stdin or the path of a hex, JSON or C array file.
+func loadConfigSource(ctx context.Context, rootConfig *rootConfig, src string, stdin io.Reader) (*ateccconf.Config608, error) {
+	if src == inputDefault || src == inputDevice || strings.HasPrefix(src, inputTemplate) {
+		return loadConfig(ctx, rootConfig, src, stdin)
+	}
+
+	r := stdin
+	if src != "-" {
+		f, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	input := inputHex
+	if b := bytes.TrimSpace(data); len(b) > 0 {
+		switch {
+		case b[0] == '{':
+			input = inputJSON
+		case bytes.ContainsRune(b, '{'):
+			input = inputC
+		}
 	}
+	return createProvisionConfig(input, bytes.NewReader(data), ateccconf.Config608{})
 }
 
 func newConfCmd(
@@ -314,8 +395,8 @@ func newConfCmd(
 	}
 
 	fs := flag.NewFlagSet("atecc config", flag.ExitOnError)
-	fs.StringVar(&cfg.input, "input", inputDefault, "Use this input for creating the provisioning configuration of the device: default (built-in), hex (stdin), json (stdin), device (read from device)")
-	fs.StringVar(&cfg.output, "output", outputHex, "Use this output for the provisioning configuration: go, hex, json, device (write to device)")
+	fs.StringVar(&cfg.input, "input", inputDefault, "Use this input for creating the provisioning configuration of the device: default (built-in), hex (stdin), json (stdin), c (C array on stdin), device (read from device), template:<name> (embedded, see config templates)")
+	fs.StringVar(&cfg.output, "output", outputHex, "Use this output for the provisioning configuration: go, c, hex, json, device (write to device)")
 	fs.BoolVar(&cfg.dry, "dry", true, "When disabled, data will be committed to device (this is irreversible!)")
 	fs.BoolVar(&cfg.json, "json", false, "Use JSON format")
 	fs.StringVar(&cfg.newAddr, "new-addr", "", "Change I2C address to this")
@@ -327,6 +408,12 @@ func newConfCmd(
 		ShortUsage: "config",
 		ShortHelp:  "Writes a general purpose configuration to test the hardware.",
 		FlagSet:    fs,
-		Exec:       cfg.Exec,
+		Subcommands: []*ffcli.Command{
+			newConfDiffCmd(rootConfig, in, out, err),
+			newConfExplainCmd(rootConfig, in, out, err),
+			newConfLintCmd(rootConfig, in, out, err),
+			newConfTemplatesCmd(rootConfig, out, err),
+		},
+		Exec: cfg.Exec,
 	})
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type confDiffConfig struct {
+	rootConfig    *rootConfig
+	in            io.Reader
+	out           io.Writer
+	err           io.Writer
+	ignoreFactory bool
+	ignoreLock    bool
+	json          bool
+}
+
+func (c *confDiffConfig) Exec(ctx context.Context, args []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "config diff\n")
+	}
+
+	if len(args) != 2 {
+		return errors.New("atecc: config diff requires two sources")
+	}
+
+	var confs [2]*ateccconf.Config608
+	for i, src := range args {
+		conf, err := loadConfigSource(ctx, c.rootConfig, src, c.in)
+		if err != nil {
+			return fmt.Errorf("%s: %w", src, err)
+		}
+		confs[i] = conf
+	}
+
+	diffs, err := ateccconf.Diff(confs[0], confs[1], ateccconf.DiffOptions{
+		IgnoreFactory: c.ignoreFactory,
+		IgnoreLock:    c.ignoreLock,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		if diffs == nil {
+			diffs = []ateccconf.Difference{}
+		}
+		err = writeJSON(c.out, diffs)
+	} else {
+		for _, d := range diffs {
+			fmt.Fprintln(c.out, d)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(diffs) > 0 {
+		return fmt.Errorf("atecc: configurations differ in %d fields", len(diffs))
+	}
+	return nil
+}
+
+func newConfDiffCmd(
+	rootConfig *rootConfig, in io.Reader, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := confDiffConfig{
+		rootConfig: rootConfig,
+		in:         in,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc config diff", flag.ExitOnError)
+	fs.BoolVar(&cfg.ignoreFactory, "ignore-factory", true, "Ignore the first 16 bytes programmed by the factory")
+	fs.BoolVar(&cfg.ignoreLock, "ignore-lock", false, "Ignore the lock bytes, including the slot locks")
+	fs.BoolVar(&cfg.json, "json", false, "output in json mode")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "diff",
+		ShortUsage: "config diff <source> <source>",
+		ShortHelp:  "Compares two configurations field by field.",
+		LongHelp: `Compares two configurations field by field.
+
+A source is either default (built-in), device (read from device),
+template:<name> (embedded, see config templates), - (stdin) or the path of a
+hex, JSON or C array configuration file. The command fails when the
+configurations differ.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
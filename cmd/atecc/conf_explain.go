@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type confExplainConfig struct {
+	rootConfig *rootConfig
+	in         io.Reader
+	out        io.Writer
+	err        io.Writer
+	input      string
+	slot       int
+	json       bool
+}
+
+func (c *confExplainConfig) Exec(ctx context.Context, _ []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "config explain\n")
+	}
+
+	conf, err := loadConfig(ctx, c.rootConfig, c.input, c.in)
+	if err != nil {
+		return err
+	}
+
+	slots := []int{c.slot}
+	if c.slot < 0 {
+		slots = slots[:0]
+		for i := range conf.SlotConfig {
+			slots = append(slots, i)
+		}
+	}
+
+	var explanations []*ateccconf.SlotExplanation
+	for _, slot := range slots {
+		e, err := ateccconf.Explain(conf, slot)
+		if err != nil {
+			return err
+		}
+		explanations = append(explanations, e)
+	}
+
+	if c.json {
+		return writeJSON(c.out, explanations)
+	} else {
+		return writeExplainText(c.out, explanations)
+	}
+}
+
+func writeExplainText(w io.Writer, explanations []*ateccconf.SlotExplanation) error {
+	yes := func(b bool) string {
+		if b {
+			return "yes"
+		} else {
+			return "no"
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SLOT\tKEY\tREAD\tWRITE\tPRIVWRITE\tDERIVEKEY\tGENKEY\tSIGN\tECDH\tLIMITS\n")
+	for _, e := range explanations {
+		// P256 slots either hold a private or a public key
+		key := e.KeyType.String()
+		if e.KeyType == ateccconf.KeyTypePrivate && !e.Private {
+			key = "public"
+		}
+
+		var limits []string
+		if e.LimitedUse {
+			limits = append(limits, "limited use")
+		}
+		if e.NoMAC {
+			limits = append(limits, "no mac")
+		}
+		if e.RequireRandom {
+			limits = append(limits, "random nonce")
+		}
+		if e.AuthKey != nil {
+			limits = append(limits, fmt.Sprintf("auth key %d", *e.AuthKey))
+		}
+		if e.PersistentDisable {
+			limits = append(limits, "persistent latch")
+		}
+		if e.Lockable {
+			limits = append(limits, "lockable")
+		}
+		if len(limits) == 0 {
+			limits = append(limits, "-")
+		}
+
+		fmt.Fprintf(
+			tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Slot, key, e.Read, e.Write, e.PrivWrite, e.DeriveKey,
+			yes(e.GenKey), e.Sign, e.ECDH, strings.Join(limits, ", "),
+		)
+	}
+	return tw.Flush()
+}
+
+func newConfExplainCmd(
+	rootConfig *rootConfig, in io.Reader, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := confExplainConfig{
+		rootConfig: rootConfig,
+		in:         in,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc config explain", flag.ExitOnError)
+	fs.StringVar(&cfg.input, "input", inputDefault, "Explain this configuration: default (built-in), hex (stdin), json (stdin), c (C array on stdin), device (read from device), template:<name> (embedded, see config templates)")
+	fs.IntVar(&cfg.slot, "slot", -1, "Only explain this slot, all slots when negative")
+	fs.BoolVar(&cfg.json, "json", false, "output in json mode")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "explain",
+		ShortUsage: "config explain",
+		ShortHelp:  "Describes who can read, write and use each slot of a configuration.",
+		LongHelp: `Describes who can read, write and use each slot of a configuration.
+
+The permissions are those in effect once the data zone has been locked. Keys
+used for encryption or derivation are given by their slot.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
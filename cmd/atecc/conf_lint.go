@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type confLintConfig struct {
+	rootConfig *rootConfig
+	in         io.Reader
+	out        io.Writer
+	err        io.Writer
+	input      string
+	severity   string
+	json       bool
+}
+
+func (c *confLintConfig) Exec(ctx context.Context, _ []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "config lint\n")
+	}
+
+	min, err := ateccconf.ParseSeverity(c.severity)
+	if err != nil {
+		return err
+	}
+
+	conf, err := loadConfig(ctx, c.rootConfig, c.input, c.in)
+	if err != nil {
+		return err
+	}
+
+	findings := []ateccconf.Finding{}
+	var errs int
+	for _, f := range ateccconf.Lint(conf) {
+		if f.Severity < min {
+			continue
+		}
+		if f.Severity == ateccconf.SeverityError {
+			errs++
+		}
+		findings = append(findings, f)
+	}
+
+	if c.json {
+		err = writeJSON(c.out, findings)
+	} else {
+		err = writeLintText(c.out, findings)
+	}
+	if err != nil {
+		return err
+	}
+
+	if errs > 0 {
+		return fmt.Errorf("atecc: configuration has %d errors", errs)
+	}
+	return nil
+}
+
+func writeLintText(w io.Writer, findings []ateccconf.Finding) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SEVERITY\tFIELD\tCHECK\tMESSAGE\n")
+	for _, f := range findings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Severity, f.Field, f.Check, f.Message)
+	}
+	return tw.Flush()
+}
+
+func newConfLintCmd(
+	rootConfig *rootConfig, in io.Reader, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := confLintConfig{
+		rootConfig: rootConfig,
+		in:         in,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc config lint", flag.ExitOnError)
+	fs.StringVar(&cfg.input, "input", inputDefault, "Lint this configuration: default (built-in), hex (stdin), json (stdin), c (C array on stdin), device (read from device), template:<name> (embedded, see config templates)")
+	fs.StringVar(&cfg.severity, "severity", ateccconf.SeverityInfo.String(), "Only report findings of at least this severity: info, warning, error")
+	fs.BoolVar(&cfg.json, "json", false, "output in json mode")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "lint",
+		ShortUsage: "config lint",
+		ShortHelp:  "Reviews a configuration for risky settings before it is locked.",
+		LongHelp: `Reviews a configuration for risky settings before it is locked.
+
+Findings are reported as info, warning or error. The command fails when any
+errors are found, which makes it usable as a check before provisioning.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
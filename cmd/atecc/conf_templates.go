@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type confTemplatesConfig struct {
+	rootConfig *rootConfig
+	out        io.Writer
+	err        io.Writer
+	json       bool
+}
+
+func (c *confTemplatesConfig) Exec(ctx context.Context, args []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "config templates\n")
+	}
+
+	templates := ateccconf.Templates()
+	if len(args) > 0 {
+		templates = templates[:0]
+		for _, name := range args {
+			t, err := ateccconf.Template(name)
+			if err != nil {
+				return err
+			}
+			templates = append(templates, *t)
+		}
+	}
+
+	if c.json {
+		return writeJSON(c.out, templates)
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	if len(args) == 0 {
+		fmt.Fprintf(tw, "NAME\tDESCRIPTION\n")
+		for _, t := range templates {
+			fmt.Fprintf(tw, "%s\t%s\n", t.Name, t.Description)
+		}
+		return tw.Flush()
+	}
+
+	fmt.Fprintf(tw, "TEMPLATE\tSLOT\tROLE\n")
+	for _, t := range templates {
+		for slot, role := range t.Roles {
+			if role == "" {
+				role = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", t.Name, slot, role)
+		}
+	}
+	return tw.Flush()
+}
+
+func newConfTemplatesCmd(
+	rootConfig *rootConfig, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := confTemplatesConfig{
+		rootConfig: rootConfig,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc config templates", flag.ExitOnError)
+	fs.BoolVar(&cfg.json, "json", false, "output in json mode")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "templates",
+		ShortUsage: "config templates [<name>...]",
+		ShortHelp:  "Lists the embedded configuration templates.",
+		LongHelp: `Lists the embedded configuration templates.
+
+When given the name of templates, the role of each slot is listed. Use a
+template as input with -input template:<name>.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+func TestConfigOutputInput(t *testing.T) {
+	testCases := []struct {
+		output string
+		input  string
+	}{
+		{outputHex, inputHex},
+		{outputJSON, inputJSON},
+		{outputC, inputC},
+	}
+
+	want := ateccconf.DefaultConfig608()
+	for _, tc := range testCases {
+		t.Run(tc.output, func(t *testing.T) {
+			var buf bytes.Buffer
+			conf := *want
+			if err := useProvisionConfig(context.Background(), true, tc.output, &buf, 0, nil, nil, &conf, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := createProvisionConfig(tc.input, strings.NewReader(buf.String()), ateccconf.Config608{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != *want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -46,6 +46,9 @@ const deviceInfoTemplate = `
 Device Part:
     {{ .Name }}
 
+Variant:
+    {{ .Variant }}
+
 Serial number:
 {{ hex .SerialNumber }}
 
@@ -114,6 +117,7 @@ func newInfoCmd(
 
 type deviceInfo struct {
 	Name               string `json:"name"`
+	Variant            string `json:"variant"`
 	SerialNumber       []byte `json:"serial_number"`
 	ConfigZone         []byte `json:"config_zone"`
 	IsConfigZoneLocked bool   `json:"is_config_zone_locked"`
@@ -122,18 +126,12 @@ type deviceInfo struct {
 }
 
 func getDeviceInfo(ctx context.Context, d *atecc.Dev) (*deviceInfo, error) {
-	var di = &deviceInfo{}
-
-	info, err := d.Revision(ctx)
-	if err != nil {
-		return nil, err
-	}
-	deviceType, err := atecc.DeviceTypeFromInfo(info)
-	if err != nil {
-		return di, err
+	var di = &deviceInfo{
+		Name:    d.DeviceType().String(),
+		Variant: d.Variant().String(),
 	}
-	di.Name = deviceType.String()
 
+	var err error
 	di.SerialNumber, err = d.SerialNumber(ctx)
 	if err != nil {
 		return di, err
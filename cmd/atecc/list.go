@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/northvolt/go-atecc/pkg/atecc"
+	"github.com/peterbourgon/ff/v3/ffcli"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/host/v3"
+)
+
+type listConfig struct {
+	rootConfig *rootConfig
+	out        io.Writer
+	err        io.Writer
+	i2c        bool
+	hid        bool
+	json       bool
+}
+
+func (c *listConfig) Exec(ctx context.Context, _ []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "list\n")
+	}
+
+	opts := atecc.DiscoverOptions{
+		HID:   c.hid,
+		Debug: newLogger(c.rootConfig.verbose),
+	}
+	if c.i2c {
+		// report buses which fail to open and discover the others
+		if _, err := host.Init(); err != nil {
+			fmt.Fprintf(c.err, "atecc: failed to initialize i2c: %v\n", err)
+		} else {
+			for _, ref := range i2creg.All() {
+				bus, err := i2creg.Open(ref.Name)
+				if err != nil {
+					fmt.Fprintf(c.err, "atecc: failed to connect to bus %s: %v\n", ref.Name, err)
+					continue
+				}
+				defer bus.Close()
+				opts.I2CBuses = append(opts.I2CBuses, bus)
+			}
+		}
+	}
+
+	descs, err := atecc.Discover(ctx, opts)
+	if err != nil {
+		// report the devices found, even if some failed to be probed
+		fmt.Fprintf(c.err, "atecc: %v\n", err)
+	}
+
+	devs := make([]listDevice, len(descs))
+	for i, desc := range descs {
+		devs[i] = listDevice{
+			Interface:          desc.IfaceType.String(),
+			Bus:                desc.Bus,
+			Address:            desc.Address,
+			Name:               desc.DeviceType.String(),
+			Variant:            desc.Variant.String(),
+			Revision:           desc.Revision,
+			SerialNumber:       desc.SerialNumber,
+			IsConfigZoneLocked: desc.ConfigZoneLocked,
+			IsDataZoneLocked:   desc.DataZoneLocked,
+		}
+	}
+
+	if c.json {
+		return writeJSON(c.out, devs)
+	} else {
+		return writeListText(c.out, devs)
+	}
+}
+
+type listDevice struct {
+	Interface          string `json:"interface"`
+	Bus                string `json:"bus"`
+	Address            uint16 `json:"address"`
+	Name               string `json:"name"`
+	Variant            string `json:"variant"`
+	Revision           []byte `json:"revision"`
+	SerialNumber       []byte `json:"serial_number"`
+	IsConfigZoneLocked bool   `json:"is_config_zone_locked"`
+	IsDataZoneLocked   bool   `json:"is_data_zone_locked"`
+}
+
+func writeListText(w io.Writer, devs []listDevice) error {
+	locked := func(b bool) string {
+		if b {
+			return "locked"
+		} else {
+			return "unlocked"
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "INTERFACE\tBUS\tADDRESS\tDEVICE\tVARIANT\tREVISION\tSERIAL NUMBER\tCONFIG\tDATA\n")
+	for _, d := range devs {
+		fmt.Fprintf(
+			tw, "%s\t%s\t0x%02X\t%s\t%s\t%X\t%X\t%s\t%s\n",
+			d.Interface, d.Bus, d.Address, d.Name, d.Variant, d.Revision, d.SerialNumber,
+			locked(d.IsConfigZoneLocked), locked(d.IsDataZoneLocked),
+		)
+	}
+	return tw.Flush()
+}
+
+func newListCmd(
+	rootConfig *rootConfig, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := listConfig{
+		rootConfig: rootConfig,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc list", flag.ExitOnError)
+	fs.BoolVar(&cfg.i2c, "i2c", true, "probe all i2c buses")
+	fs.BoolVar(&cfg.hid, "hid", true, "probe all hid kits")
+	fs.BoolVar(&cfg.json, "json", false, "output in json mode")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "list",
+		ShortUsage: "list",
+		ShortHelp:  "Lists all reachable devices.",
+		FlagSet:    fs,
+		Exec:       cfg.Exec,
+	})
+}
@@ -27,7 +27,12 @@ func main() {
 	rootCmd.Subcommands = []*ffcli.Command{
 		newConfCmd(cfg, in, out, err),
 		newInfoCmd(cfg, out, err),
+		newListCmd(cfg, out, err),
+		newManifestCmd(cfg, in, out, err),
+		newOTPCmd(cfg, out, err),
+		newProvisionCmd(cfg, out, err),
 		newRandCmd(cfg, out, err),
+		newServeCmd(cfg, err),
 		newSignCmd(cfg, in, out, err),
 	}
 
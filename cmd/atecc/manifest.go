@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/northvolt/go-atecc/pkg/manifest"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type manifestConfig struct {
+	rootConfig *rootConfig
+}
+
+func (c *manifestConfig) Exec(context.Context, []string) error {
+	return flag.ErrHelp
+}
+
+// readPrivateKey reads a PEM encoded PKCS #8 or SEC 1 P-256 private key.
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := manifest.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, path)
+	}
+	return key, nil
+}
+
+// readCertificates reads all PEM encoded certificates, or a DER certificate.
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := manifest.ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, path)
+	}
+	return certs, nil
+}
+
+// readCertificate reads the first certificate.
+func readCertificate(path string) (*x509.Certificate, error) {
+	if path == "" {
+		return nil, errors.New("atecc: missing certificate")
+	}
+	certs, err := readCertificates(path)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+func newManifestCmd(
+	rootConfig *rootConfig, in io.Reader, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := manifestConfig{
+		rootConfig: rootConfig,
+	}
+
+	fs := flag.NewFlagSet("atecc manifest", flag.ExitOnError)
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "manifest",
+		ShortUsage: "manifest <subcommand>",
+		ShortHelp:  "Creates and verifies Trust Platform manifests.",
+		LongHelp: `Creates and verifies Trust Platform manifests.
+
+A manifest lists devices by their serial number together with the public keys
+and certificates of their slots, signed by the party provisioning them.
+Microchip issues manifests for pre-provisioned Trust&GO devices.`,
+		FlagSet: fs,
+		Subcommands: []*ffcli.Command{
+			newManifestCreateCmd(rootConfig, out, err),
+			newManifestVerifyCmd(rootConfig, in, out, err),
+		},
+		Exec: cfg.Exec,
+	})
+}
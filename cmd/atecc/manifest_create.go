@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/northvolt/go-atecc/pkg/manifest"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type manifestCreateConfig struct {
+	rootConfig  *rootConfig
+	out         io.Writer
+	err         io.Writer
+	key         string
+	signerCert  string
+	slots       string
+	certs       map[int]string
+	provisioner string
+	groupID     string
+}
+
+func (c *manifestCreateConfig) Exec(ctx context.Context, _ []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "manifest create\n")
+	}
+
+	if c.key == "" {
+		return errors.New("atecc: missing signer key, see -key")
+	}
+	signer, err := readPrivateKey(c.key)
+	if err != nil {
+		return err
+	}
+	var signerCert *x509.Certificate
+	if c.signerCert != "" {
+		if signerCert, err = readCertificate(c.signerCert); err != nil {
+			return err
+		}
+	}
+
+	d, closer, err := newATECC(ctx, c.rootConfig)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	var slots []int
+	if c.slots != "" {
+		for _, s := range strings.Split(c.slots, ",") {
+			slot, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return fmt.Errorf("atecc: invalid slot %q", s)
+			}
+			slots = append(slots, slot)
+		}
+	} else {
+		config, err := d.ReadConfigZone(ctx)
+		if err != nil {
+			return err
+		}
+		conf, err := ateccconf.UnmarshalConfig608(config)
+		if err != nil {
+			return err
+		}
+		slots = manifest.PrivateKeySlots(conf)
+	}
+
+	se, err := manifest.FromDevice(ctx, d, slots)
+	if err != nil {
+		return err
+	}
+	if c.provisioner != "" {
+		se.Provisioner = &manifest.Organization{OrganizationName: c.provisioner}
+	}
+	se.GroupID = c.groupID
+
+	for i, k := range se.PublicKeySet.Keys {
+		slot, _ := k.Slot()
+		path, ok := c.certs[slot]
+		if !ok {
+			continue
+		}
+		certs, err := readCertificates(path)
+		if err != nil {
+			return err
+		}
+		for _, cert := range certs {
+			k.Certificates = append(k.Certificates, cert.Raw)
+		}
+		se.PublicKeySet.Keys[i] = k
+	}
+
+	sse, err := manifest.Sign(se, signer, signerCert)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.out, manifest.Manifest{*sse})
+}
+
+func newManifestCreateCmd(
+	rootConfig *rootConfig, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := manifestCreateConfig{
+		rootConfig: rootConfig,
+		out:        out,
+		err:        err,
+		certs:      make(map[int]string),
+	}
+
+	fs := flag.NewFlagSet("atecc manifest create", flag.ExitOnError)
+	fs.StringVar(&cfg.key, "key", "", "PEM encoded P-256 private key signing the manifest")
+	fs.StringVar(&cfg.signerCert, "signer-cert", "", "PEM encoded certificate of the signing key, referenced by the manifest")
+	fs.StringVar(&cfg.slots, "slots", "", "comma separated slots to include, defaults to the private key slots of the device")
+	fs.Func("cert", "certificate chain of a slot as `slot=path`, may be repeated", func(s string) error {
+		slot, path, ok := strings.Cut(s, "=")
+		n, err := strconv.Atoi(slot)
+		if !ok || err != nil || n < 0 || n > 15 {
+			return fmt.Errorf("invalid slot certificate %q", s)
+		}
+		cfg.certs[n] = path
+		return nil
+	})
+	fs.StringVar(&cfg.provisioner, "provisioner", "", "organization name of the provisioner")
+	fs.StringVar(&cfg.groupID, "group-id", "", "group id of the device")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "create",
+		ShortUsage: "manifest create -key <path> [flags]",
+		ShortHelp:  "Creates a signed manifest of the device.",
+		LongHelp: `Creates a signed manifest of the device.
+
+The manifest holds the serial number of the device and the public keys of its
+slots, together with any certificates given using -cert. It is signed by the
+-key and written to stdout as a manifest with a single entry.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
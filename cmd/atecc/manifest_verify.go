@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/northvolt/go-atecc/pkg/manifest"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type manifestVerifyConfig struct {
+	rootConfig *rootConfig
+	in         io.Reader
+	out        io.Writer
+	err        io.Writer
+	signerCert string
+	device     bool
+	json       bool
+}
+
+type manifestVerifyResult struct {
+	UniqueID   string `json:"unique_id"`
+	Model      string `json:"model,omitempty"`
+	PartNumber string `json:"part_number,omitempty"`
+	Keys       int    `json:"keys"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (c *manifestVerifyConfig) Exec(ctx context.Context, args []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "manifest verify\n")
+	}
+
+	if len(args) != 1 {
+		return errors.New("atecc: manifest verify requires a manifest")
+	}
+	signerCert, err := readCertificate(c.signerCert)
+	if err != nil {
+		return fmt.Errorf("%w, see -signer-cert", err)
+	}
+
+	r := c.in
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	m, err := manifest.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	// Only verify the entry of the connected device
+	var dev manifest.Device
+	if c.device {
+		d, closer, err := newATECC(ctx, c.rootConfig)
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+
+		sn, err := d.SerialNumber(ctx)
+		if err != nil {
+			return err
+		}
+		sse, ok := m.Find(manifest.UniqueID(sn))
+		if !ok {
+			return fmt.Errorf("atecc: device %s not found in manifest", manifest.UniqueID(sn))
+		}
+		m = manifest.Manifest{*sse}
+		dev = d
+	}
+
+	var (
+		results []manifestVerifyResult
+		failed  int
+	)
+	for _, sse := range m {
+		res := manifestVerifyResult{UniqueID: sse.Header.UniqueID}
+		se, err := sse.Verify(signerCert)
+		if err == nil && dev != nil {
+			err = manifest.VerifyDevice(ctx, dev, se)
+		}
+		if se != nil {
+			res.Model = se.Model
+			res.PartNumber = se.PartNumber
+			res.Keys = len(se.PublicKeySet.Keys)
+		}
+		if err != nil {
+			res.Error = err.Error()
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	if c.json {
+		if results == nil {
+			results = []manifestVerifyResult{}
+		}
+		err = writeJSON(c.out, results)
+	} else {
+		tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "UNIQUE ID\tMODEL\tKEYS\tSTATUS\n")
+		for _, res := range results {
+			status := "ok"
+			if res.Error != "" {
+				status = res.Error
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", res.UniqueID, res.Model, res.Keys, status)
+		}
+		err = tw.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("atecc: %d of %d manifest entries failed verification", failed, len(results))
+	}
+	return nil
+}
+
+func newManifestVerifyCmd(
+	rootConfig *rootConfig, in io.Reader, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := manifestVerifyConfig{
+		rootConfig: rootConfig,
+		in:         in,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc manifest verify", flag.ExitOnError)
+	fs.StringVar(&cfg.signerCert, "signer-cert", "", "PEM encoded certificate of the manifest signer")
+	fs.BoolVar(&cfg.device, "device", false, "Verify the entry of the connected device against it")
+	fs.BoolVar(&cfg.json, "json", false, "output in json mode")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "verify",
+		ShortUsage: "manifest verify -signer-cert <path> [flags] <manifest>",
+		ShortHelp:  "Verifies the signatures of a manifest.",
+		LongHelp: `Verifies the signatures of a manifest.
+
+The manifest is read from the path, or stdin if -. Each entry is verified using
+the certificate of the signer, such as the manifest signer certificate
+published by Microchip alongside the manifests of Trust&GO devices.
+
+With -device, only the entry of the connected device is verified, and the
+public keys in the manifest are compared with the keys held by the device.
+The command fails when any entry fails verification.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type otpConfig struct {
+	rootConfig *rootConfig
+}
+
+func (c *otpConfig) Exec(context.Context, []string) error {
+	return flag.ErrHelp
+}
+
+// readOTPLayout reads a JSON OTP layout, e.g.
+//
+//	[{"name": "revision", "offset": 0, "size": 2, "format": "uint"}]
+func readOTPLayout(path string) (ateccconf.OTPLayout, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var layout ateccconf.OTPLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, err
+	}
+	return layout, layout.Validate()
+}
+
+func newOTPCmd(
+	rootConfig *rootConfig, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := otpConfig{
+		rootConfig: rootConfig,
+	}
+
+	fs := flag.NewFlagSet("atecc otp", flag.ExitOnError)
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "otp",
+		ShortUsage: "otp <subcommand>",
+		ShortHelp:  "Reads and writes the one-time programmable zone.",
+		LongHelp: `Reads and writes the one-time programmable zone.
+
+The 64 byte OTP zone can only be written in 32 byte blocks before the data zone
+is locked, and only read once it is locked. Once locked, it is read-only on
+ATECC608 devices. On ATSHA204 and ATECC508 devices the OTP mode decides if it
+can still be written, clearing bits only.
+
+A layout names the values stored in the zone, such as a hardware revision or a
+manufacturing lot. It is a JSON array of fields:
+
+  [
+    {"name": "revision", "offset": 0, "size": 2, "format": "uint"},
+    {"name": "lot", "offset": 4, "size": 12, "format": "string"}
+  ]
+
+The format is hex (default), string or uint.`,
+		FlagSet: fs,
+		Subcommands: []*ffcli.Command{
+			newOTPReadCmd(rootConfig, out, err),
+			newOTPWriteCmd(rootConfig, out, err),
+		},
+		Exec: cfg.Exec,
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type otpReadConfig struct {
+	rootConfig *rootConfig
+	out        io.Writer
+	err        io.Writer
+	layout     string
+	json       bool
+}
+
+type otpInfo struct {
+	Usage  ateccconf.OTPUsage   `json:"usage"`
+	OTP    string               `json:"otp"`
+	Fields []ateccconf.OTPValue `json:"fields,omitempty"`
+}
+
+func (c *otpReadConfig) Exec(ctx context.Context, _ []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "otp read\n")
+	}
+
+	layout, err := readOTPLayout(c.layout)
+	if err != nil {
+		return err
+	}
+
+	d, closer, err := newATECC(ctx, c.rootConfig)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	usage, err := d.OTPUsage(ctx)
+	if err != nil {
+		return err
+	}
+	otp := make([]byte, ateccconf.OTPSize)
+	if _, err := d.ReadOTP(ctx, 0, otp); err != nil {
+		return err
+	}
+	fields, err := layout.Decode(otp)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return writeJSON(c.out, otpInfo{
+			Usage:  usage,
+			OTP:    hex.EncodeToString(otp),
+			Fields: fields,
+		})
+	}
+
+	fmt.Fprintln(c.out, "OTP usage:")
+	fmt.Fprintf(c.out, "    %s\n\n", usage)
+	fmt.Fprintln(c.out, "OTP zone:")
+	fmt.Fprintln(c.out, prettyHex(otp))
+	if len(fields) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(c.out)
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "FIELD\tVALUE\n")
+	for _, f := range fields {
+		fmt.Fprintf(tw, "%s\t%s\n", f.Name, f.Value)
+	}
+	return tw.Flush()
+}
+
+func newOTPReadCmd(
+	rootConfig *rootConfig, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := otpReadConfig{
+		rootConfig: rootConfig,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc otp read", flag.ExitOnError)
+	fs.StringVar(&cfg.layout, "layout", "", "JSON layout of the fields stored in the zone")
+	fs.BoolVar(&cfg.json, "json", false, "output in json mode")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "read",
+		ShortUsage: "otp read [flags]",
+		ShortHelp:  "Reads the OTP zone and describes how it can be used.",
+		FlagSet:    fs,
+		Exec:       cfg.Exec,
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type otpWriteConfig struct {
+	rootConfig *rootConfig
+	out        io.Writer
+	err        io.Writer
+	offset     int
+	layout     string
+	set        []string
+	dry        bool
+}
+
+func (c *otpWriteConfig) Exec(ctx context.Context, args []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "otp write\n")
+	}
+
+	if (len(args) == 0) == (len(c.set) == 0) {
+		return errors.New("atecc: otp write requires either hex data or -set")
+	}
+	layout, err := readOTPLayout(c.layout)
+	if err != nil {
+		return err
+	}
+
+	d, closer, err := newATECC(ctx, c.rootConfig)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	usage, err := d.OTPUsage(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "OTP usage:")
+	fmt.Fprintf(c.out, "    %s\n\n", usage)
+
+	var (
+		offset = c.offset
+		data   []byte
+	)
+	if len(args) > 0 {
+		if data, err = ateccconf.ParseHex([]byte(strings.Join(args, " "))); err != nil {
+			return err
+		}
+	} else {
+		// Set the fields of the zone, keeping other values when readable
+		offset = 0
+		data = make([]byte, ateccconf.OTPSize)
+		if usage.CanRead() {
+			if _, err := d.ReadOTP(ctx, 0, data); err != nil {
+				return err
+			}
+		}
+		for _, s := range c.set {
+			name, value, _ := strings.Cut(s, "=")
+			if err := layout.Encode(data, name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(c.out, "Data at offset %d:\n", offset)
+	fmt.Fprintln(c.out, prettyHex(data))
+	if c.dry {
+		fmt.Fprintln(c.out, `
+WARNING! This operation is irreversible! Once written, the OTP zone can not be
+changed, except for clearing bits in consumption mode.
+
+To continue with this operation, re-run with -dry=false.`)
+		return nil
+	}
+	return d.WriteOTP(ctx, offset, data)
+}
+
+func newOTPWriteCmd(
+	rootConfig *rootConfig, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := otpWriteConfig{
+		rootConfig: rootConfig,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc otp write", flag.ExitOnError)
+	fs.IntVar(&cfg.offset, "offset", 0, "Offset in bytes to write the hex data to")
+	fs.StringVar(&cfg.layout, "layout", "", "JSON layout of the fields stored in the zone")
+	fs.Func("set", "Set a field of the layout as `name=value`, may be repeated", func(s string) error {
+		if !strings.Contains(s, "=") {
+			return fmt.Errorf("invalid field %q", s)
+		}
+		cfg.set = append(cfg.set, s)
+		return nil
+	})
+	fs.BoolVar(&cfg.dry, "dry", true, "When disabled, data will be committed to device (this is irreversible!)")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "write",
+		ShortUsage: "otp write [flags] [<hex>]",
+		ShortHelp:  "Writes hex data or the fields of a layout to the OTP zone.",
+		LongHelp: `Writes hex data or the fields of a layout to the OTP zone.
+
+Either write hex data at -offset, or set fields of the -layout using -set. Set
+fields are written together with the rest of the zone, which is kept if it can
+be read and zero otherwise.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/northvolt/go-atecc/pkg/provision"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type provisionConfig struct {
+	rootConfig *rootConfig
+	out        io.Writer
+	err        io.Writer
+	plan       string
+	record     string
+	dry        bool
+	json       bool
+}
+
+func (c *provisionConfig) Exec(ctx context.Context, _ []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "provision\n")
+	}
+
+	if c.plan == "" {
+		return errors.New("atecc: missing plan, see -plan")
+	}
+	plan, err := provision.Load(os.DirFS(filepath.Dir(c.plan)), filepath.Base(c.plan))
+	if err != nil {
+		return err
+	}
+
+	d, closer, err := newATECC(ctx, c.rootConfig)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	rec, runErr := provision.Run(ctx, d, plan, provision.Options{DryRun: c.dry})
+
+	if c.record != "" {
+		f, err := os.OpenFile(c.record, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return errors.Join(runErr, err)
+		}
+		err = writeJSON(f, rec)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return errors.Join(runErr, err)
+		}
+	}
+
+	if c.json {
+		err = writeJSON(c.out, rec)
+	} else {
+		err = writeProvisionRecord(c.out, rec)
+	}
+	if err != nil {
+		return errors.Join(runErr, err)
+	}
+	return runErr
+}
+
+func writeProvisionRecord(w io.Writer, rec *provision.Record) error {
+	fmt.Fprintf(w, "Serial number: %s\n\n", rec.SerialNumber)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ACTION\tSLOT\tSTATUS\tREASON\n")
+	for _, s := range rec.Steps {
+		slot := "-"
+		if s.Slot != nil {
+			slot = strconv.Itoa(*s.Slot)
+		}
+		reason := s.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Action, slot, s.Status, reason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if rec.DryRun {
+		fmt.Fprintln(w, `
+WARNING! Locking is irreversible! Once the zones are locked, the configuration
+and the slots can no longer be changed.
+
+To continue with this operation, re-run with -dry=false.`)
+	} else if !rec.Complete {
+		fmt.Fprintln(w, "\nProvisioning is incomplete, re-run to resume.")
+	}
+	return nil
+}
+
+func newProvisionCmd(
+	rootConfig *rootConfig, out io.Writer, err io.Writer,
+) *ffcli.Command {
+	cfg := provisionConfig{
+		rootConfig: rootConfig,
+		out:        out,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc provision", flag.ExitOnError)
+	fs.StringVar(&cfg.plan, "plan", "", "JSON provisioning plan")
+	fs.StringVar(&cfg.record, "record", "", "Write the audit record to this new file")
+	fs.BoolVar(&cfg.dry, "dry", true, "When disabled, data will be committed to device (this is irreversible!)")
+	fs.BoolVar(&cfg.json, "json", false, "Output the audit record in JSON format")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "provision",
+		ShortUsage: "provision -plan <path> [flags]",
+		ShortHelp:  "Provisions the device according to a plan.",
+		LongHelp: `Provisions the device according to a plan.
+
+The plan declares the configuration template, the I²C address, the contents of
+each slot, the OTP zone and which zones to lock:
+
+  {
+    "template": "pkcs11",
+    "i2c_address": "0x35",
+    "slots": [
+      {"slot": 0, "generate_key": true, "lock": true},
+      {"slot": 2, "private_key": "device.key"},
+      {"slot": 8, "certificate": "device.pem"},
+      {"slot": 9, "data": "0102"}
+    ],
+    "otp": "0102",
+    "lock": {"config": true, "data": true}
+  }
+
+Files are relative to the plan. Steps of zones which are already locked are
+skipped, so a failed run can be resumed by running the plan again. Keys are
+generated again until the data zone is locked.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
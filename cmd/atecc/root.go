@@ -12,21 +12,35 @@ type rootConfig struct {
 	iface               string
 	bus                 int
 	addr                string
+	port                string
+	baud                int
+	tls                 bool
+	tlsCA               string
+	tlsClientCert       string
+	tlsClientKey        string
 	trustPlatformFormat bool
 	devIndex            int
 	// devInterface        string
 	devIdentity string
+	strict      bool
 }
 
 func (c *rootConfig) registerFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&c.verbose, "v", false, "increase log verbosity")
-	fs.StringVar(&c.iface, "i", "i2c", "interface type, hid or i2c")
+	fs.StringVar(&c.iface, "i", "i2c", "interface type, hid, i2c, i2c-dev, serial or net")
 	fs.IntVar(&c.bus, "bus", 0, "i2c bus to use")
-	fs.StringVar(&c.addr, "addr", "", "i2c address in hex")
+	fs.StringVar(&c.addr, "addr", "", "i2c address in hex, or host:port for net")
+	fs.StringVar(&c.port, "port", "/dev/ttyACM0", "serial port to use")
+	fs.IntVar(&c.baud, "baud", 115200, "serial port baud rate")
+	fs.BoolVar(&c.tls, "tls", false, "use TLS for net")
+	fs.StringVar(&c.tlsCA, "tls-ca", "", "CA certificate file to verify the net server, implies -tls")
+	fs.StringVar(&c.tlsClientCert, "tls-client-cert", "", "client certificate file presented to the net server, implies -tls")
+	fs.StringVar(&c.tlsClientKey, "tls-client-key", "", "client private key file")
 	// fs.StringVar(&c.devInterface, "dev-interface", "auto", "dev kit interface type")
 	// TODO: fallback to i2c address (change that to empty string) when empty
 	fs.IntVar(&c.devIndex, "dev-index", 0, "device index when enumerating")
 	fs.StringVar(&c.devIdentity, "dev-identity", "", "device identity is the I2C address or the bus number for the SWI interface device")
+	fs.BoolVar(&c.strict, "strict", false, "fail if the detected device type is not ATECC608")
 	fs.BoolVar(&c.trustPlatformFormat, "trust-platform-format", false, "use cryptoauthlib trust platform format instead of default common format")
 }
 
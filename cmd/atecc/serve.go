@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/northvolt/go-atecc/pkg/atecc"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+type serveConfig struct {
+	rootConfig *rootConfig
+	err        io.Writer
+	listen     string
+	tlsCert    string
+	tlsKey     string
+	clientCA   string
+}
+
+func (c *serveConfig) Exec(ctx context.Context, _ []string) error {
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "serve\n")
+	}
+
+	d, closer, err := newATECC(ctx, c.rootConfig)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	l, err := net.Listen("tcp", c.listen)
+	if err != nil {
+		return err
+	}
+	if addr, ok := l.Addr().(*net.TCPAddr); ok && !addr.IP.IsLoopback() && c.clientCA == "" {
+		fmt.Fprintf(c.err, `WARNING! Listening on %s without client authentication!
+Anyone who can reach this address can write, lock or reconfigure the device.
+Listen on a loopback address, or require client certificates with -tls-client-ca.
+`, l.Addr())
+	}
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+	if c.rootConfig.verbose {
+		fmt.Fprintf(c.err, "listening on %s\n", l.Addr())
+	}
+
+	err = atecc.NewKitServer(d).Serve(ctx, l)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// tlsConfig returns the TLS config of the server, or nil if TLS is disabled.
+func (c *serveConfig) tlsConfig() (*tls.Config, error) {
+	if c.tlsCert == "" && c.tlsKey == "" {
+		if c.clientCA != "" {
+			return nil, errors.New("atecc: -tls-client-ca requires -tls-cert and -tls-key")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.tlsCert, c.tlsKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if c.clientCA != "" {
+		ca, err := os.ReadFile(c.clientCA)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(ca) {
+			return nil, errors.New("atecc: no certificates found in client CA file")
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+func newServeCmd(rootConfig *rootConfig, err io.Writer) *ffcli.Command {
+	cfg := serveConfig{
+		rootConfig: rootConfig,
+		err:        err,
+	}
+
+	fs := flag.NewFlagSet("atecc serve", flag.ExitOnError)
+	fs.StringVar(&cfg.listen, "listen", "127.0.0.1:4141", "address to listen on")
+	fs.StringVar(&cfg.tlsCert, "tls-cert", "", "TLS certificate file, enables TLS")
+	fs.StringVar(&cfg.tlsKey, "tls-key", "", "TLS private key file")
+	fs.StringVar(&cfg.clientCA, "tls-client-ca", "", "CA certificate file to require and verify client certificates")
+	rootConfig.registerFlags(fs)
+
+	return addLongHelp(&ffcli.Command{
+		Name:       "serve",
+		ShortUsage: "serve -listen 127.0.0.1:4141",
+		ShortHelp:  "Serves the device over TCP using the kit protocol.",
+		LongHelp: `Serves the device over TCP using the kit protocol.
+
+Use the net interface to connect to the served device from another host, for
+example: atecc -i net -addr rack1:4141 info
+
+The kit protocol relays commands to the device as is, including writes and
+locks. By default, the server only listens on the loopback interface. To serve
+other hosts, enable TLS and require client certificates signed by a CA:
+
+  atecc serve -listen :4141 -tls-cert server.pem -tls-key server.key \
+    -tls-client-ca clients.pem
+
+Clients present their certificate using -tls-client-cert and -tls-client-key.`,
+		FlagSet: fs,
+		Exec:    cfg.Exec,
+	})
+}
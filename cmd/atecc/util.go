@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -25,11 +26,32 @@ const (
 )
 
 func newATECC(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, error) {
+	d, closer, err := openATECC(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The configured device type is replaced by the detected one, unless
+	// -strict is set. Warn even when not verbose, as the commands assume an
+	// ATECC608.
+	if dt := d.DeviceType(); dt != atecc.DeviceATECC608 {
+		fmt.Fprintf(os.Stderr, "atecc: warning: detected %s instead of %s, use -strict to fail instead\n", dt, atecc.DeviceATECC608)
+	}
+	return d, closer, nil
+}
+
+func openATECC(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, error) {
 	switch c.iface {
 	case "i2c":
 		return newATECC_I2C(ctx, c)
+	case "i2c-dev":
+		return newATECC_LinuxI2C(ctx, c)
 	case "hid":
 		return newATECC_HID(ctx, c)
+	case "serial":
+		return newATECC_Serial(ctx, c)
+	case "net":
+		return newATECC_Net(ctx, c)
 	default:
 		return nil, nil, errors.New("atecc: unknown interface")
 	}
@@ -51,9 +73,41 @@ func newATECC_I2C(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, er
 
 	cfg := atecc.ConfigATECCX08A_I2CDefault(bus)
 	cfg.Debug = newLogger(c.verbose)
+	cfg.StrictDeviceType = c.strict
 	cfg.I2C.Address = i2cAddress
 	d, err := atecc.NewI2CDev(ctx, cfg)
-	return d, bus, err
+	if err != nil {
+		_ = bus.Close()
+		return nil, nil, err
+	}
+	return d, devCloser{d, bus}, nil
+}
+
+// devCloser closes the device before the bus it is connected to.
+type devCloser struct {
+	d   *atecc.Dev
+	bus io.Closer
+}
+
+func (c devCloser) Close() error {
+	return errors.Join(c.d.Close(), c.bus.Close())
+}
+
+func newATECC_LinuxI2C(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, error) {
+	i2cAddress, err := getI2CAddress(c.addr, c.trustPlatformFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := atecc.ConfigATECCX08A_LinuxI2CDefault(fmt.Sprintf("/dev/i2c-%d", c.bus))
+	cfg.Debug = newLogger(c.verbose)
+	cfg.StrictDeviceType = c.strict
+	cfg.LinuxI2C.Address = i2cAddress
+	d, err := atecc.NewLinuxI2CDev(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, d, nil
 }
 
 func newATECC_HID(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, error) {
@@ -64,10 +118,71 @@ func newATECC_HID(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, er
 
 	cfg := atecc.ConfigATECCX08A_KitHIDDefault()
 	cfg.Debug = newLogger(c.verbose)
+	cfg.StrictDeviceType = c.strict
 	cfg.HID.DevIndex = c.devIndex
 	cfg.HID.DevIdentity = identity
 
-	return atecc.NewHIDDev(ctx, cfg)
+	d, err := atecc.NewHIDDev(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, d, nil
+}
+
+func newATECC_Serial(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, error) {
+	identity, err := getHIDDeviceIdentity(c.devIdentity, c.trustPlatformFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := atecc.ConfigATECCX08A_KitSerialDefault(c.port)
+	cfg.Debug = newLogger(c.verbose)
+	cfg.StrictDeviceType = c.strict
+	cfg.KitSerial.BaudRate = c.baud
+	cfg.KitSerial.DevIndex = c.devIndex
+	cfg.KitSerial.DevIdentity = identity
+
+	d, err := atecc.NewKitSerialDev(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, d, nil
+}
+
+func newATECC_Net(ctx context.Context, c *rootConfig) (*atecc.Dev, io.Closer, error) {
+	if c.addr == "" {
+		return nil, nil, errors.New("atecc: missing net address")
+	}
+
+	cfg := atecc.ConfigATECCX08A_NetDefault(c.addr)
+	cfg.Debug = newLogger(c.verbose)
+	cfg.StrictDeviceType = c.strict
+	if c.tls || c.tlsCA != "" || c.tlsClientCert != "" {
+		cfg.Net.TLS = &tls.Config{}
+	}
+	if c.tlsClientCert != "" || c.tlsClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.tlsClientCert, c.tlsClientKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.Net.TLS.Certificates = []tls.Certificate{cert}
+	}
+	if c.tlsCA != "" {
+		ca, err := os.ReadFile(c.tlsCA)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.Net.TLS.RootCAs = x509.NewCertPool()
+		if !cfg.Net.TLS.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, nil, errors.New("atecc: no certificates found in CA file")
+		}
+	}
+
+	d, err := atecc.NewNetDev(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, d, nil
 }
 
 func getI2CAddress(addrStr string, trustPlatformFormat bool) (uint16, error) {
@@ -26,6 +26,7 @@ const (
 	deviceStateUnknown deviceState = iota
 	deviceStateIdle
 	deviceStateActive
+	deviceStateSleep
 )
 
 // Zone is a configuration zone.
@@ -39,18 +40,30 @@ const (
 )
 
 const (
-	zoneSizeConfig = 128
-	zoneSizeOTP    = 64
+	zoneSizeConfig    = 128
+	zoneSizeConfig204 = ateccconf.ConfigSize204
+	zoneSizeOTP       = 64
+	zoneSizeSlot204   = 32
 )
 
-func getZoneSize(zone Zone, slot uint16) (int, error) {
+func getZoneSize(dt DeviceType, zone Zone, slot uint16) (int, error) {
+	if dt.isCA2() {
+		return getZoneSizeCA2(zone, slot)
+	}
 	switch zone {
 	case ZoneConfig:
+		if dt == DeviceATSHA204 {
+			return zoneSizeConfig204, nil
+		}
 		return zoneSizeConfig, nil
 	case ZoneOTP:
 		return zoneSizeOTP, nil
 	case ZoneData:
-		if slot < 8 {
+		if slot >= 16 {
+			return 0, errors.New("atecc: invalid slot received")
+		} else if dt == DeviceATSHA204 {
+			return zoneSizeSlot204, nil
+		} else if slot < 8 {
 			return 36, nil
 		} else if slot == 8 {
 			return 416, nil
@@ -72,6 +85,15 @@ type Dev struct {
 	log   Logger
 
 	clockDivider ateccconf.ClockDivider
+	sn           []byte
+	variant      Variant
+
+	// watchdog is the time the device stays awake after wake.
+	watchdog time.Duration
+	// awake is the time the device was last woken up.
+	awake time.Time
+	// sessions is the number of active KeepAwake calls.
+	sessions int
 }
 
 // New returns a new ATECC device using the supplied HAL for communication.
@@ -88,22 +110,105 @@ func New(ctx context.Context, hal HAL, cfg IfaceConfig) (*Dev, error) {
 }
 
 func (d *Dev) init(ctx context.Context) error {
-	var buf [1]byte
-	_, err := d.readBytesZone(ctx, ZoneConfig, 0, ateccconf.ChipModeOffset, buf[:])
+	revision, err := d.detect(ctx)
 	if err != nil {
 		return err
 	}
 
-	var conf ateccconf.Config608
-	err = ateccconf.UnmarshalPartial(buf[:], ateccconf.ChipModeOffset, &conf)
+	// The first block holds both the serial number and the chip mode.
+	var buf [atcaBlockSize]byte
+	_, err = d.readBytesZone(ctx, ZoneConfig, 0, 0, buf[:])
 	if err != nil {
 		return err
 	}
 
-	d.clockDivider = conf.ChipMode.ClockDivider()
+	switch d.cfg.DeviceType {
+	case DeviceATECC608:
+		var conf ateccconf.Config608
+		if err := ateccconf.UnmarshalPartial(buf[:], 0, &conf); err != nil {
+			return err
+		}
+		d.clockDivider = conf.ChipMode.ClockDivider()
+		d.watchdog = watchdogDuration(conf.ChipMode)
+	case DeviceATECC508:
+		var conf ateccconf.Config508
+		if err := ateccconf.UnmarshalPartial(buf[:], 0, &conf); err != nil {
+			return err
+		}
+		d.watchdog = watchdogDuration(conf.ChipMode)
+	case DeviceATSHA204, DeviceECC204, DeviceSHA104, DeviceSHA105, DeviceTA010:
+		// The watchdog duration is not configurable.
+		d.watchdog = watchdogDurationShort
+	default:
+		return fmt.Errorf("atecc: unsupported device type %s", d.cfg.DeviceType)
+	}
+
+	d.sn = serialNumberFromConfig(buf[:])
+	d.variant = variantFromInfo(d.cfg.DeviceType, revision, buf[:])
 	return nil
 }
 
+// detect detects the device type using the revision.
+//
+// The detected device type replaces the configured one, unless they differ and
+// StrictDeviceType is set. The revision is returned.
+func (d *Dev) detect(ctx context.Context) ([]byte, error) {
+	p, err := newInfoCommand(infoModeRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	// The execution time depends on the device type, which is not known yet.
+	var revision [4]byte
+	n, err := d.executeResponseAfter(ctx, p, revision[:], getMaxExecutionTime(atcaInfo))
+	if err != nil {
+		return nil, err
+	}
+
+	dt, err := DeviceTypeFromInfo(revision[:n])
+	if err != nil {
+		if d.cfg.StrictDeviceType {
+			return nil, fmt.Errorf("%w: %v", ErrDeviceTypeMismatch, err)
+		}
+		d.log.Printf("atecc: warning: revision %x: %v, assuming %s\n", revision[:n], err, d.cfg.DeviceType)
+		return revision[:n], nil
+	}
+
+	if dt != d.cfg.DeviceType {
+		if d.cfg.StrictDeviceType {
+			return nil, fmt.Errorf("%w: configured %s, detected %s", ErrDeviceTypeMismatch, d.cfg.DeviceType, dt)
+		}
+		d.log.Printf("atecc: warning: configured %s, detected %s\n", d.cfg.DeviceType, dt)
+		d.cfg.DeviceType = dt
+	}
+	return revision[:n], nil
+}
+
+// Close puts the device to sleep and releases the transport.
+//
+// Sleep is the lowest power mode of the device. The device is idled instead if
+// the HAL does not implement HALSleeper, and the transport is only released if
+// the HAL implements io.Closer. The transport is released even if the device
+// could not be put to sleep. The device must not be used after it has been
+// closed.
+func (d *Dev) Close() error {
+	err := d.Sleep()
+	if errors.Is(err, errSleepUnsupported) {
+		err = d.Idle()
+	}
+	return errors.Join(err, halClose(d.hal))
+}
+
+// DeviceType returns the device type detected when the device was opened.
+func (d *Dev) DeviceType() DeviceType {
+	return d.cfg.DeviceType
+}
+
+// Variant returns the silicon variant detected when the device was opened.
+func (d *Dev) Variant() Variant {
+	return d.variant
+}
+
 // Revision gets the device revision.
 //
 // This information is hard coded into the device. Use it to determine the
@@ -134,14 +239,24 @@ func (d *Dev) SerialNumber(ctx context.Context) ([]byte, error) {
 	return d.serialNumber(ctx)
 }
 
+// ReadZone reads a block or word of the zone.
+//
+// On ECC204, SHA104, SHA105 and TA010 devices, the config subzone is given by
+// slot and pages of the data slots are given by block. The offset is ignored.
 func (d *Dev) ReadZone(ctx context.Context, zone Zone, slot uint16, block uint8, offset uint8, b []byte) (int, error) {
 	return d.readZone(ctx, zone, slot, block, offset, b)
 }
 
 // ReadConfigZone reads the complete device configuration zone.
+//
+// The size of the configuration zone depends on the device type.
 func (d *Dev) ReadConfigZone(ctx context.Context) ([]byte, error) {
-	var buf [128]byte
-	n, err := d.readConfigZone(ctx, buf[:])
+	size, err := getZoneSize(d.cfg.DeviceType, ZoneConfig, 0)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := d.readConfigZone(ctx, buf)
 	return buf[:n], err
 }
 
@@ -160,6 +275,10 @@ func (d *Dev) IsDataZoneLocked(ctx context.Context) (bool, error) {
 }
 
 func (d *Dev) IsLocked(ctx context.Context, zone Zone) (bool, error) {
+	if d.cfg.DeviceType.isCA2() {
+		return d.isLockedCA2(ctx, zone)
+	}
+
 	var buf [atcaWordSize]byte
 
 	// Read the word with the lock bytes
@@ -194,10 +313,50 @@ func (d *Dev) LockDataZone(ctx context.Context) error {
 	return d.lockDataZone(ctx)
 }
 
+// LockDataSlot locks the slot.
+//
+// The lock command is sent as by cryptoauthlib, with a zero summary. Use
+// LockDataSlotCRC to only lock the slot if it holds the expected contents.
 func (d *Dev) LockDataSlot(ctx context.Context, slot uint8) error {
 	return d.lockDataSlot(ctx, slot)
 }
 
+// LockConfigZoneCRC locks the configuration zone if it holds the expected
+// contents.
+//
+// The expected contents is the full configuration zone, including the first
+// 16 bytes and the lock bytes as currently held by the device. The device
+// refuses to lock if the CRC of the zone does not match.
+func (d *Dev) LockConfigZoneCRC(ctx context.Context, expected []byte) error {
+	size, err := getZoneSize(d.cfg.DeviceType, ZoneConfig, 0)
+	if err != nil {
+		return err
+	}
+	return d.lockCRC(ctx, lockZoneConfig, 0, expected, size)
+}
+
+// LockDataZoneCRC locks the data and OTP zones if they hold the expected
+// contents.
+//
+// The expected contents is all slots in order, followed by the OTP zone. The
+// device refuses to lock if the CRC of the zones does not match.
+func (d *Dev) LockDataZoneCRC(ctx context.Context, expected []byte) error {
+	size, err := d.dataZoneSize()
+	if err != nil {
+		return err
+	}
+	return d.lockCRC(ctx, lockZoneData, 0, expected, size)
+}
+
+// LockDataSlotCRC locks the slot if it holds the expected contents.
+func (d *Dev) LockDataSlotCRC(ctx context.Context, slot uint8, expected []byte) error {
+	size, err := getZoneSize(d.cfg.DeviceType, ZoneData, uint16(slot))
+	if err != nil {
+		return err
+	}
+	return d.lockCRC(ctx, lockZoneDataSlot, lockMode(slot<<2), expected, size)
+}
+
 // GenerateKey generates a new random private key in slot/handle.
 func (d *Dev) GenerateKey(ctx context.Context, slot uint8) (crypto.PublicKey, error) {
 	var pk [64]byte
@@ -236,6 +395,84 @@ func (d *Dev) PublicKey(ctx context.Context, slot uint8) (crypto.PublicKey, erro
 	}, nil
 }
 
+// OTPUsage returns how the OTP zone can be accessed, given the OTP mode and
+// the lock state of the data zone.
+func (d *Dev) OTPUsage(ctx context.Context) (ateccconf.OTPUsage, error) {
+	return d.otpUsage(ctx)
+}
+
+// ReadOTP reads len(b) bytes of the OTP zone starting at offset.
+//
+// The OTP zone can only be read once the data zone is locked. In legacy OTP
+// mode, the zone is read a word at a time.
+func (d *Dev) ReadOTP(ctx context.Context, offset int, b []byte) (int, error) {
+	if offset < 0 || offset+len(b) > ateccconf.OTPSize {
+		return 0, fmt.Errorf("atecc: otp read of %d bytes at offset %d is outside of the zone", len(b), offset)
+	}
+	usage, err := d.otpUsage(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !usage.CanRead() {
+		return 0, errors.New("atecc: otp zone can not be read before the data zone is locked")
+	}
+	if usage.Mode == ateccconf.OTPModeLegacy && usage.DataLocked {
+		return d.readOTPWords(ctx, offset, b)
+	}
+	return d.readBytesZone(ctx, ZoneOTP, 0, offset, b)
+}
+
+// WriteOTP writes the data to the OTP zone starting at offset.
+//
+// Before the data zone is locked, offset and length must be multiples of 32
+// bytes. Once locked, writes are only permitted in consumption OTP mode on
+// ATSHA204 and ATECC508 devices, in multiples of 4 bytes, and may only clear
+// bits. The OTP zone of ATECC608 devices is read-only once locked.
+func (d *Dev) WriteOTP(ctx context.Context, offset int, data []byte) error {
+	if offset < 0 || offset+len(data) > ateccconf.OTPSize {
+		return fmt.Errorf("atecc: otp write of %d bytes at offset %d is outside of the zone", len(data), offset)
+	}
+	usage, err := d.otpUsage(ctx)
+	if err != nil {
+		return err
+	}
+	if !usage.CanWrite() {
+		return fmt.Errorf("atecc: otp zone is read-only in %s mode once the data zone is locked", usage.Mode)
+	}
+	if size := usage.WriteSizes[0]; offset%size != 0 || len(data)%size != 0 {
+		return fmt.Errorf("atecc: otp writes must be multiples of %d bytes", size)
+	}
+
+	if usage.ClearOnly {
+		current := make([]byte, len(data))
+		if _, err := d.readBytesZone(ctx, ZoneOTP, 0, offset, current); err != nil {
+			return err
+		}
+		for i := range data {
+			if data[i]&^current[i] != 0 {
+				return fmt.Errorf("atecc: otp write sets bits at offset %d, only clearing bits is permitted", offset+i)
+			}
+		}
+	}
+
+	_, err = d.writeBytesZone(ctx, ZoneOTP, 0, offset, data)
+	return err
+}
+
+// PrivWrite writes the P-256 private key to the slot.
+//
+// The key is written in the clear, which the device only allows before the
+// data zone is locked. Encrypted writes to a locked data zone are not
+// supported.
+func (d *Dev) PrivWrite(ctx context.Context, slot uint8, priv *ecdsa.PrivateKey) error {
+	if priv.Curve != elliptic.P256() {
+		return errors.New("atecc: only P-256 private keys are supported")
+	}
+	var key [32]byte
+	priv.D.FillBytes(key[:])
+	return d.privWrite(ctx, slot, key[:])
+}
+
 // Sign signs the message using the private key in the specified slot.
 //
 // This function executes the sign command to sign a 32-byte external message
@@ -305,9 +542,10 @@ func (d *Dev) VerifyExtern(ctx context.Context, msg, sig []byte, pub crypto.Publ
 // If ZoneConfig is unlocked, it may be written to. If ZoneData is unlocked,
 // 32-byte writes are allowed to slots and OTP.
 //
-// Offset and length must be multiples of 32 or the write will fail.
+// Offset and length must be multiples of 32 or the write will fail. Use Slot
+// to write data slots larger than 256 bytes.
 func (d *Dev) WriteBytesZone(ctx context.Context, zone Zone, slot uint16, offset uint8, data []byte) error {
-	_, err := d.writeBytesZone(ctx, zone, slot, offset, data)
+	_, err := d.writeBytesZone(ctx, zone, slot, int(offset), data)
 	return err
 }
 
@@ -336,16 +574,41 @@ func (d *Dev) execute(ctx context.Context, p *packet) error {
 // The command is encoded and transfered to the device. It returns the number
 // of bytes read into recv together with any error encountered.
 func (d *Dev) executeResponse(ctx context.Context, p *packet, recv []byte) (int, error) {
+	t, err := getExecutionTime(d.cfg.DeviceType, d.clockDivider, p.opcode)
+	if err != nil {
+		return 0, err
+	}
+	return d.executeResponseAfter(ctx, p, recv, t)
+}
+
+// executeResponseAfter executes the command and reads the response once the
+// execution time t has passed.
+func (d *Dev) executeResponseAfter(ctx context.Context, p *packet, recv []byte, t time.Duration) (int, error) {
 	b, err := d.enc.Encode(p)
 	if err != nil {
 		return 0, err
 	}
 
+	// The watchdog puts the device to sleep, make sure we finish in time.
+	if left := d.awakeFor(); d.state == deviceStateActive && left < t {
+		if d.sessions > 0 {
+			return 0, fmt.Errorf(
+				"%w: %s requires %v, %v left",
+				ErrWatchdogTimeout, opcodeName(p.opcode), t, left,
+			)
+		}
+		// Restart the watchdog by idling the device before waking it again.
+		_ = d.hal.Idle()
+		d.state = deviceStateIdle
+	}
+
 	// send the command to the device
 	for i := -1; i < d.cfg.RxRetries; i++ {
 		if d.state != deviceStateActive {
+			start := time.Now()
 			if err = d.hal.Wake(); err == nil {
 				d.state = deviceStateActive
+				d.awake = start
 			}
 		}
 
@@ -364,29 +627,20 @@ func (d *Dev) executeResponse(ctx context.Context, p *packet, recv []byte) (int,
 		return 0, err
 	}
 
-	// Put device back into idle mode once finished. This function is called even
-	// if we would encounter a panic.
+	// Put device back into idle mode once finished, unless it is kept awake.
+	// This function is called even if we would encounter a panic.
 	defer func() {
-		_ = d.hal.Idle()
-		d.state = deviceStateIdle
+		if d.sessions == 0 {
+			_ = d.hal.Idle()
+			d.state = deviceStateIdle
+		}
 	}()
 
-	// wait for the operation to finish
-	t, err := getExecutionTime(d.cfg.DeviceType, d.clockDivider, p.opcode)
-	if err != nil {
-		return 0, err
-	}
-	select {
-	case <-ctx.Done():
-		return 0, ctx.Err()
-	case <-time.After(t):
-	}
-
 	// make room for 1 byte size and 2 byte crc
 	buf := make([]byte, len(recv)+3)
-	size, err := d.hal.Read(buf[:])
+	size, err := d.receive(ctx, buf, t)
 	if err != nil {
-		if errors.Is(err, errRecvBuffer) {
+		if errors.Is(err, ErrRecvBuffer) {
 			fmt.Fprintf(os.Stderr, "atecc: receive buffer overflowed\n")
 			debug.PrintStack()
 		}
@@ -409,6 +663,7 @@ func (d *Dev) executeResponse(ctx context.Context, p *packet, recv []byte) (int,
 	// error responses are always 4 bytes long
 	if size == 4 {
 		if err = validateResponseStatusCode(sizedResponse[1:]); err != nil {
+			err = newStatusError(p, sizedResponse[1], d.sn, err)
 			if d.log != nullLogger {
 				d.log.Printf("invalid status code: %v\n", err)
 				d.log.Printf("%s", string(debug.Stack()))
@@ -420,6 +675,55 @@ func (d *Dev) executeResponse(ctx context.Context, p *packet, recv []byte) (int,
 	return copy(recv, sizedResponse[1:]), nil
 }
 
+// receive reads the response into buf once the command has finished.
+//
+// Unless polling is configured, the response is read once the execution time
+// t has passed. Otherwise the response is read repeatedly until the device
+// responds or the maximum polling time has passed.
+func (d *Dev) receive(ctx context.Context, buf []byte, t time.Duration) (int, error) {
+	poll := d.cfg.Poll
+	if poll.Interval <= 0 {
+		if err := sleep(ctx, t); err != nil {
+			return 0, err
+		}
+		return d.hal.Read(buf)
+	}
+
+	maxTime := poll.MaxTime
+	if maxTime <= 0 {
+		maxTime = t
+	}
+	deadline := time.Now().Add(maxTime)
+	if err := sleep(ctx, poll.InitialDelay); err != nil {
+		return 0, err
+	}
+	for {
+		// The device does not respond until the command has finished.
+		n, err := d.hal.Read(buf)
+		if err == nil || errors.Is(err, ErrRecvBuffer) || !time.Now().Before(deadline) {
+			return n, err
+		}
+		if err := sleep(ctx, poll.Interval); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// sleep pauses for the duration or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 type randReader struct {
 	ctx context.Context
 	d   *Dev
@@ -0,0 +1,257 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+// flagBenchI2CDev is the i2c-dev device file of a real device to benchmark.
+//
+// The device is expected to hold a private key in slot 0.
+var flagBenchI2CDev = flag.String("atecc-i2c-dev", "", "benchmark the device at i2c-dev `path`, e.g. /dev/i2c-1")
+
+func TestPoll(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.lock()
+	d := newSimDev(t, sim)
+	d.cfg.Poll = PollConfig{Interval: time.Millisecond}
+	sim.busy = 10 * time.Millisecond
+
+	// the response is read as soon as the device has finished
+	start := time.Now()
+	if _, err := d.GenerateKey(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	max, _ := getExecutionTime(DeviceATECC608, d.clockDivider, atcaGenKey)
+	if elapsed := time.Since(start); elapsed >= max {
+		t.Errorf("took %v, want less than %v", elapsed, max)
+	}
+
+	// polling gives up after the maximum time
+	sim.busy = time.Second
+	d.cfg.Poll.MaxTime = 20 * time.Millisecond
+	if _, err := d.Revision(ctx); err == nil {
+		t.Error("expected error while busy")
+	}
+}
+
+func TestLockCRC(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	d := newSimDev(t, sim)
+
+	// a corrupted config zone is not locked
+	expected := append([]byte(nil), sim.config[:]...)
+	sim.config[20] ^= 0x01
+	if err := d.LockConfigZoneCRC(ctx, expected); !errors.Is(err, ErrExecution) {
+		t.Fatalf("got %v, want %v", err, ErrExecution)
+	}
+	if err := d.LockConfigZoneCRC(ctx, expected[:64]); err == nil {
+		t.Fatal("expected error for short config zone")
+	}
+	sim.config[20] ^= 0x01
+	kc := sim.keyConfig(8).WithLockable(true)
+	sim.config[96+2*8], sim.config[97+2*8] = kc.Bits1, kc.Bits2
+	expected[96+2*8], expected[97+2*8] = kc.Bits1, kc.Bits2
+	if err := d.LockConfigZoneCRC(ctx, expected); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.configLocked() {
+		t.Fatal("config zone not locked")
+	}
+
+	size, err := d.dataZoneSize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = make([]byte, size)
+	sim.data[8][0] = 0x01
+	if err := d.LockDataZoneCRC(ctx, expected); !errors.Is(err, ErrExecution) {
+		t.Fatalf("got %v, want %v", err, ErrExecution)
+	}
+	expected[8*36] = 0x01
+	if err := d.LockDataZoneCRC(ctx, expected); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.dataLocked() {
+		t.Fatal("data zone not locked")
+	}
+
+	slot := make([]byte, len(sim.data[8]))
+	if err := d.LockDataSlotCRC(ctx, 8, slot); !errors.Is(err, ErrExecution) {
+		t.Fatalf("got %v, want %v", err, ErrExecution)
+	}
+	slot[0] = 0x01
+	if err := d.LockDataSlotCRC(ctx, 8, slot); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.slotLocked(8) {
+		t.Fatal("slot not locked")
+	}
+
+	config, err := d.ReadConfigZone(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf ateccconf.Config608
+	if err := ateccconf.Unmarshal(config, &conf); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 16; i++ {
+		if got := conf.SlotLocked.IsLocked(i); got != (i == 8) {
+			t.Errorf("slot %d: got locked %v", i, got)
+		}
+	}
+}
+
+func TestPrivWrite(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.config[87] = byte(ateccconf.LockStateLocked)
+	kc := sim.keyConfig(2).WithPrivate(true)
+	sim.config[96+2*2], sim.config[97+2*2] = kc.Bits1, kc.Bits2
+	d := newSimDev(t, sim)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.PrivWrite(ctx, 2, priv); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := d.PublicKey(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.PublicKey.Equal(pub) {
+		t.Error("public key does not match the written private key")
+	}
+
+	// writing in the clear is prohibited once the data zone is locked
+	sim.config[86] = byte(ateccconf.LockStateLocked)
+	if err := d.PrivWrite(ctx, 2, priv); !errors.Is(err, ErrExecution) {
+		t.Errorf("got %v, want %v", err, ErrExecution)
+	}
+}
+
+func TestOTP(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.config[87] = byte(ateccconf.LockStateLocked)
+	d := newSimDev(t, sim)
+
+	want := make([]byte, 32)
+	copy(want, "rev B lot 2024-17")
+	if err := d.WriteOTP(ctx, 32, want); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteOTP(ctx, 4, want[:4]); err == nil {
+		t.Error("expected error for word write before data lock")
+	}
+	if err := d.WriteOTP(ctx, 48, want); err == nil {
+		t.Error("expected error for write outside of the zone")
+	}
+	if _, err := d.ReadOTP(ctx, 0, make([]byte, 4)); err == nil {
+		t.Error("expected error for read before data lock")
+	}
+
+	sim.config[86] = byte(ateccconf.LockStateLocked)
+	got := make([]byte, 20)
+	if _, err := d.ReadOTP(ctx, 36, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want[4:24]) {
+		t.Errorf("got %q, want %q", got, want[4:24])
+	}
+	if err := d.WriteOTP(ctx, 0, want); err == nil {
+		t.Error("expected error for write once the data zone is locked")
+	}
+
+	usage, err := d.OTPUsage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Mode != ateccconf.OTPModeReadOnly || usage.CanWrite() || !usage.CanRead() {
+		t.Errorf("got usage %s", usage)
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	forEachBenchDev(b, func(b *testing.B, d *Dev) {
+		ctx := context.Background()
+		digest := sha256.Sum256([]byte("hello"))
+		for i := 0; i < b.N; i++ {
+			if _, err := d.Sign(ctx, 0, digest[:]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkRandom(b *testing.B) {
+	forEachBenchDev(b, func(b *testing.B, d *Dev) {
+		var buf [32]byte
+		r := d.Random(context.Background())
+		b.SetBytes(int64(len(buf)))
+		for i := 0; i < b.N; i++ {
+			if _, err := io.ReadFull(r, buf[:]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// forEachBenchDev runs the benchmark against the simulator and, if given, the
+// real device, both with and without polling.
+func forEachBenchDev(b *testing.B, fn func(b *testing.B, d *Dev)) {
+	polls := []struct {
+		name string
+		poll PollConfig
+	}{
+		{"wait", PollConfig{}},
+		{"poll", DefaultPollConfig},
+	}
+
+	for _, p := range polls {
+		b.Run("sim/"+p.name, func(b *testing.B) {
+			sim := newSimDevice()
+			d := newSimDev(b, sim)
+			d.cfg.Poll = p.poll
+			sim.busy = 2 * time.Millisecond
+			if _, err := d.GenerateKey(context.Background(), 0); err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			fn(b, d)
+		})
+	}
+
+	if *flagBenchI2CDev == "" {
+		return
+	}
+	for _, p := range polls {
+		b.Run("i2c-dev/"+p.name, func(b *testing.B) {
+			cfg := ConfigATECCX08A_LinuxI2CDefault(*flagBenchI2CDev)
+			cfg.Poll = p.poll
+			d, err := NewLinuxI2CDev(context.Background(), cfg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer d.Close()
+			b.ResetTimer()
+			fn(b, d)
+		})
+	}
+}
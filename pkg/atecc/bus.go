@@ -0,0 +1,228 @@
+package atecc
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// I²C target address range used when scanning.
+const (
+	busScanAddressFirst uint16 = 0x08
+	busScanAddressLast  uint16 = 0x77
+)
+
+// Bus manages several devices sharing a single I²C bus.
+//
+// The wake condition is generated on the bus itself and wakes every device
+// connected to it. Devices not taking part in the command would then be put
+// to sleep by their watchdog, losing any volatile state. Bus tracks the
+// devices found or opened through it and puts the ones woken by accident back
+// into idle mode, where their volatile state is retained.
+//
+// The ATECC608 lacks the Pause command available on earlier devices, which is
+// why idle is used to put the other devices into a known state.
+//
+// Access to the bus is serialized, allowing the devices to be used from
+// different goroutines. A single Dev must still not be used concurrently.
+type Bus struct {
+	cfg IfaceConfig
+
+	mu sync.Mutex
+	// addrs contains the addresses of all devices known to be on the bus.
+	addrs []uint16
+	// members contains the devices currently opened.
+	members []*busHAL
+}
+
+// NewBus returns a manager for the I²C bus in cfg.I2C.Bus.
+//
+// The configuration is used for all devices opened on the bus, except for the
+// address. The bus is owned by the manager and closed by Close.
+func NewBus(cfg IfaceConfig) *Bus {
+	return &Bus{cfg: cfg}
+}
+
+// Open returns the device at the I²C target address.
+func (b *Bus) Open(ctx context.Context, addr uint16) (*Dev, error) {
+	cfg := b.cfg
+	cfg.I2C.Address = addr
+
+	h := &busHAL{halI2C: newHALI2C(cfg.I2C.Bus, addr, cfg), b: b}
+	b.mu.Lock()
+	b.addAddress(addr)
+	b.members = append(b.members, h)
+	b.mu.Unlock()
+
+	d, err := New(ctx, h, cfg)
+	if err != nil {
+		_ = h.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Scan returns all devices responding on the bus, ordered by address.
+//
+// A single wake condition is generated, after which every address is probed
+// for the wake response. All found devices are put into idle mode before
+// they are opened.
+func (b *Bus) Scan(ctx context.Context) ([]*Dev, error) {
+	addrs, err := b.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	devs := make([]*Dev, 0, len(addrs))
+	for _, addr := range addrs {
+		d, err := b.Open(ctx, addr)
+		if err != nil {
+			for _, d := range devs {
+				_ = d.Close()
+			}
+			return nil, err
+		}
+		devs = append(devs, d)
+	}
+	return devs, nil
+}
+
+func (b *Bus) scan() ([]uint16, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		addrs   []uint16
+		err     error
+		retries = b.cfg.RxRetries
+	)
+	if retries < 1 {
+		retries = 1
+	}
+	for i := 0; i < retries && len(addrs) == 0; i++ {
+		h := newHALI2C(b.cfg.I2C.Bus, 0, b.cfg)
+		h.pulse()
+
+		for addr := busScanAddressFirst; addr <= busScanAddressLast; addr++ {
+			var r [4]byte
+			if err = b.cfg.I2C.Bus.Tx(addr, nil, r[:]); err != nil {
+				continue
+			}
+			if err = checkWakeUp(r[:]); err != nil {
+				continue
+			}
+			addrs = append(addrs, addr)
+			b.addAddress(addr)
+			_ = b.cfg.I2C.Bus.Tx(addr, []byte{wordIdle}, nil)
+		}
+	}
+
+	if len(addrs) == 0 && err != nil {
+		return nil, err
+	}
+	return addrs, nil
+}
+
+// addAddress adds the address to the known device addresses.
+func (b *Bus) addAddress(addr uint16) {
+	for _, a := range b.addrs {
+		if a == addr {
+			return
+		}
+	}
+	b.addrs = append(b.addrs, addr)
+}
+
+// Close closes the bus.
+//
+// All devices opened on the bus should be closed first.
+func (b *Bus) Close() error {
+	if c, ok := b.cfg.I2C.Bus.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// busHAL is the HAL of a device on a managed bus.
+type busHAL struct {
+	*halI2C
+	b *Bus
+
+	// awake is set when the device is expected to be awake.
+	awake bool
+}
+
+func (h *busHAL) Write(data []byte) (int, error) {
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+	return h.halI2C.Write(data)
+}
+
+func (h *busHAL) Read(buf []byte) (int, error) {
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+	return h.halI2C.Read(buf)
+}
+
+func (h *busHAL) Idle() error {
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+	h.awake = false
+	return h.halI2C.Idle()
+}
+
+func (h *busHAL) Sleep() error {
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+	h.awake = false
+	return h.halI2C.Sleep()
+}
+
+// Wake wakes the device up and idles the other devices woken by accident.
+//
+// Devices which are already awake are left alone, as they are in the middle
+// of a session.
+func (h *busHAL) Wake() error {
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+
+	awake := map[uint16]bool{h.addr(): true}
+	for _, m := range h.b.members {
+		if m.awake {
+			awake[m.addr()] = true
+		}
+	}
+
+	err := h.halI2C.Wake()
+	if err == nil {
+		h.awake = true
+	}
+
+	// The error is ignored, as the device might not have been woken up.
+	for _, addr := range h.b.addrs {
+		if !awake[addr] {
+			_ = h.b.cfg.I2C.Bus.Tx(addr, []byte{wordIdle}, nil)
+		}
+	}
+	return err
+}
+
+func (h *busHAL) addr() uint16 {
+	return h.cfg.I2C.Address
+}
+
+// Close removes the device from the bus.
+//
+// The device is still idled when woken up by others. The bus itself is left
+// open.
+func (h *busHAL) Close() error {
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+	for i, m := range h.b.members {
+		if m == h {
+			h.b.members = append(h.b.members[:i], h.b.members[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
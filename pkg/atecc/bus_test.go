@@ -0,0 +1,165 @@
+package atecc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/physic"
+)
+
+// fakeI2CBus is an I²C bus with simulated devices connected to it.
+type fakeI2CBus struct {
+	sims   map[uint16]*simDevice
+	resp   map[uint16][]byte // response being read per address
+	closed bool
+}
+
+func newFakeI2CBus(addrs ...uint16) *fakeI2CBus {
+	b := &fakeI2CBus{
+		sims: make(map[uint16]*simDevice),
+		resp: make(map[uint16][]byte),
+	}
+	for _, addr := range addrs {
+		b.sims[addr] = newSimDevice()
+	}
+	return b
+}
+
+func (b *fakeI2CBus) String() string                    { return "fake" }
+func (b *fakeI2CBus) SetSpeed(f physic.Frequency) error { return nil }
+
+func (b *fakeI2CBus) Close() error {
+	b.closed = true
+	return nil
+}
+
+func (b *fakeI2CBus) Tx(addr uint16, w, r []byte) error {
+	// the wake condition wakes every device on the bus
+	if addr == 0 {
+		for addr, sim := range b.sims {
+			if !sim.awake {
+				_ = sim.Wake()
+				b.resp[addr] = []byte{0x04, 0x11, 0x33, 0x43}
+			}
+		}
+		return errI2CNack
+	}
+
+	sim, ok := b.sims[addr]
+	if !ok || !sim.awake {
+		return errI2CNack
+	}
+	if len(w) > 0 {
+		switch w[0] {
+		case 0x00: // reset
+		case wordCommand:
+			_, _ = sim.Write(w[1:])
+		case wordIdle:
+			_ = sim.Idle()
+		case wordSleep:
+			_ = sim.Sleep()
+		default:
+			return errors.New("i2c: unknown word address")
+		}
+	}
+	if len(r) > 0 {
+		if len(b.resp[addr]) == 0 {
+			var buf [256]byte
+			n, err := sim.Read(buf[:])
+			if err != nil {
+				return errI2CNack
+			}
+			b.resp[addr] = buf[:n]
+		}
+		n := copy(r, b.resp[addr])
+		b.resp[addr] = b.resp[addr][n:]
+	}
+	return nil
+}
+
+func newFakeBusConfig(bus *fakeI2CBus) IfaceConfig {
+	cfg := ConfigATECCX08A_I2CDefault(bus)
+	cfg.WakeDelay = time.Microsecond
+	cfg.RxRetries = 2
+	return cfg
+}
+
+func TestBusScan(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeI2CBus(0x60, 0x35, 0x36)
+	b := NewBus(newFakeBusConfig(bus))
+
+	devs, err := b.Scan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devs) != 3 {
+		t.Fatalf("got %d devices, want 3", len(devs))
+	}
+	for i, addr := range []uint16{0x35, 0x36, 0x60} {
+		if devs[i].cfg.I2C.Address != addr {
+			t.Errorf("%d: got address 0x%02x, want 0x%02x", i, devs[i].cfg.I2C.Address, addr)
+		}
+	}
+	for addr, sim := range bus.sims {
+		if sim.awake {
+			t.Errorf("0x%02x: device left awake", addr)
+		}
+	}
+
+	for _, d := range devs {
+		if err := d.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bus.closed {
+		t.Error("bus not closed")
+	}
+}
+
+func TestBusIdlesOthers(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeI2CBus(0x60, 0x61)
+	b := NewBus(newFakeBusConfig(bus))
+
+	a, err := b.Open(ctx, 0x60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := b.Open(ctx, 0x61)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the session keeps the other device awake while the first is used
+	err = other.KeepAwake(ctx, func(ctx context.Context) error {
+		if err := other.nonceLoad(ctx, nonceTargetTempKey, make([]byte, 32)); err != nil {
+			return err
+		}
+		if _, err := a.Revision(ctx); err != nil {
+			return err
+		}
+		if !bus.sims[0x61].awake {
+			t.Error("device in session was idled")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Revision(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if bus.sims[0x61].awake {
+		t.Error("device woken by wake condition left awake")
+	}
+	if bus.sims[0x61].tempKey == nil {
+		t.Error("TempKey lost")
+	}
+}
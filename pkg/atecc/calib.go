@@ -3,6 +3,7 @@ package atecc
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/northvolt/go-atecc/pkg/ateccconf"
 )
@@ -14,17 +15,56 @@ const (
 )
 
 func (d *Dev) lockConfigZone(ctx context.Context) error {
+	if d.cfg.DeviceType.isCA2() {
+		return d.lockConfigZoneCA2(ctx)
+	}
 	return d.lock(ctx, lockZoneConfig, lockModeNoCRC, 0)
 }
 
 func (d *Dev) lockDataZone(ctx context.Context) error {
+	if d.cfg.DeviceType.isCA2() {
+		return d.lockDataZoneCA2(ctx)
+	}
 	return d.lock(ctx, lockZoneData, lockModeNoCRC, 0)
 }
 
 func (d *Dev) lockDataSlot(ctx context.Context, slot uint8) error {
+	if d.cfg.DeviceType.isCA2() {
+		return d.lockCA2(ctx, lockZoneCA2Data, uint16(slot))
+	}
 	return d.lock(ctx, lockZoneDataSlot, lockMode(slot<<2), 0)
 }
 
+// lockCRC locks the zone or slot if its contents match the expected.
+//
+// The device computes the CRC of the contents and refuses to lock on a
+// mismatch, e.g. when a previous write was corrupted.
+func (d *Dev) lockCRC(ctx context.Context, zone lockZone, mode lockMode, expected []byte, size int) error {
+	if d.cfg.DeviceType.isCA2() {
+		return fmt.Errorf("%w: lock with crc on %s", ErrUnsupportedCommand, d.cfg.DeviceType)
+	}
+	if len(expected) != size {
+		return fmt.Errorf("atecc: expected %d bytes to lock, got %d", size, len(expected))
+	}
+	return d.lock(ctx, zone, mode, ateccconf.LockCRC(expected))
+}
+
+// dataZoneSize returns the size of all slots and the OTP zone.
+func (d *Dev) dataZoneSize() (int, error) {
+	size, err := getZoneSize(d.cfg.DeviceType, ZoneOTP, 0)
+	if err != nil {
+		return 0, err
+	}
+	for slot := uint16(0); slot < 16; slot++ {
+		n, err := getZoneSize(d.cfg.DeviceType, ZoneData, slot)
+		if err != nil {
+			return 0, err
+		}
+		size += n
+	}
+	return size, nil
+}
+
 func (d *Dev) lock(ctx context.Context, zone lockZone, mode lockMode, crc uint16) error {
 	command, err := newLockCommand(zone, mode, crc)
 	if err != nil {
@@ -34,66 +74,56 @@ func (d *Dev) lock(ctx context.Context, zone lockZone, mode lockMode, crc uint16
 	return d.execute(ctx, command)
 }
 
-// TODO: rewrite in idiomatic go
+// readBytesZone reads len(data) bytes of the zone, or of the data slot,
+// starting at offset.
+//
+// Blocks are read where they fit within the zone, and words otherwise, since a
+// block read past the end of a slot fails.
 func (d *Dev) readBytesZone(ctx context.Context, zone Zone, slot uint16, offset int, data []byte) (int, error) {
-	var buf [atcaBlockSize]byte
-	var dataIdx = 0
-	var curOffset = 0
+	if d.cfg.DeviceType.isCA2() {
+		return d.readBytesZoneCA2(ctx, zone, slot, offset, data)
+	}
 
 	// Always succeed reading 0 bytes
 	if len(data) == 0 {
 		return 0, nil
 	}
 
-	zoneSize, err := getZoneSize(zone, 0)
+	zoneSize, err := getZoneSize(d.cfg.DeviceType, zone, slot)
 	if err != nil {
 		return 0, err
 	}
-
-	// make sure we don't read past end of zone
-	if offset+len(data) > zoneSize {
-		return 0, errors.New("atecc: invalid parameter recieved")
+	if offset < 0 || offset+len(data) > zoneSize {
+		return 0, fmt.Errorf("atecc: read of %d bytes at offset %d is outside of the %d byte zone", len(data), offset, zoneSize)
 	}
 
-	readBuf := buf[:atcaBlockSize]
-	curBlock := offset / atcaBlockSize
-	for dataIdx < len(data) {
-		// Read word size when we have less than a block left to read
-		if len(readBuf) == atcaBlockSize && zoneSize-curBlock*atcaBlockSize < atcaBlockSize {
-			readBuf = buf[:atcaWordSize]
-			curOffset = ((dataIdx + offset) / atcaWordSize) % (atcaBlockSize / atcaWordSize)
-		}
-
-		n, err := d.readZone(ctx, zone, slot, uint8(curBlock), uint8(curOffset), readBuf)
-		if err != nil {
-			return dataIdx, err
-		}
-
-		readBufIdx := 0
-		readOffset := curBlock*atcaBlockSize + curOffset*atcaWordSize
-		// Check if read data starts before the requested chunk
-		if readOffset < offset {
-			readBufIdx = offset - readOffset
-		}
+	var (
+		buf [atcaBlockSize]byte
+		n   int
+	)
+	for n < len(data) {
+		pos := offset + n
+		block, word := pos/atcaBlockSize, pos%atcaBlockSize/atcaWordSize
 
-		// Calculate how much data from the read buffer we want to copy
-		copyLength := n - readBufIdx
-		if len(data)-dataIdx < copyLength {
-			copyLength = len(data) - dataIdx
+		start, size := block*atcaBlockSize, atcaBlockSize
+		if start+atcaBlockSize > zoneSize {
+			start, size = start+word*atcaWordSize, atcaWordSize
+		} else {
+			word = 0
 		}
 
-		copy(data[dataIdx:], readBuf[readBufIdx:readBufIdx+copyLength])
-		dataIdx += copyLength
-		if n == atcaBlockSize {
-			curBlock += 1
-		} else {
-			curOffset += 1
+		if _, err := d.readZone(ctx, zone, slot, uint8(block), uint8(word), buf[:size]); err != nil {
+			return n, err
 		}
+		n += copy(data[n:], buf[pos-start:size])
 	}
-	return dataIdx, nil
+	return n, nil
 }
 
 func (d *Dev) readZone(ctx context.Context, zone Zone, slot uint16, block uint8, offset uint8, data []byte) (int, error) {
+	if d.cfg.DeviceType.isCA2() {
+		return d.readZoneCA2(ctx, zone, slot, block, data)
+	}
 	if len(data) != atcaBlockSize && len(data) != atcaWordSize {
 		return 0, errors.New("atecc: invalid read zone size")
 	}
@@ -144,21 +174,21 @@ func getAddr(zone Zone, slot uint16, block uint8, offset uint8) (uint16, error)
 // serialNumber reads the config and extracts the 9 bytes serial number.
 func (d *Dev) serialNumber(ctx context.Context) ([]byte, error) {
 	var buf [atcaBlockSize]byte
-	_, err := d.readZone(ctx, ZoneConfig, 0, 0, 0, buf[:])
+	_, err := d.readBytesZone(ctx, ZoneConfig, 0, 0, buf[:])
 	if err != nil {
 		return nil, err
 	}
 
-	var conf ateccconf.Config608
-	err = ateccconf.UnmarshalPartial(buf[:], 0, &conf)
-	if err != nil {
-		return nil, err
-	}
+	return serialNumberFromConfig(buf[:]), nil
+}
 
+// serialNumberFromConfig extracts the 9 bytes serial number from the first
+// block of the config, which has the same layout on all devices.
+func serialNumberFromConfig(config []byte) []byte {
 	var serialNumber [9]byte
-	copy(serialNumber[:], conf.SN03[:])
-	copy(serialNumber[4:], conf.SN48[:])
-	return serialNumber[:], nil
+	copy(serialNumber[:], config[0:4])
+	copy(serialNumber[4:], config[8:13])
+	return serialNumber[:]
 }
 
 func (d *Dev) generateKey(ctx context.Context, keyId uint8, publicKey []byte) (int, error) {
@@ -169,6 +199,20 @@ func (d *Dev) publicKey(ctx context.Context, keyId uint8, publicKey []byte) (int
 	return d.genKeyBase(ctx, genKeyModePublic, keyId, nil, publicKey)
 }
 
+// privWrite writes the 32 byte private key to the slot in the clear.
+//
+// Writing in the clear is only allowed before the data zone is locked.
+func (d *Dev) privWrite(ctx context.Context, keyId uint8, key []byte) error {
+	var value [36]byte
+	copy(value[4:], key)
+	command, err := newPrivWriteCommand(privWriteModeClear, keyId, value[:], nil)
+	if err != nil {
+		return err
+	}
+
+	return d.execute(ctx, command)
+}
+
 // getKeyBase issues the GenKey command which does various things.
 //
 // This function generates and executes the GenKey command, which generate a
@@ -203,11 +247,6 @@ func (d *Dev) genKeyBase(ctx context.Context, mode uint8, keyId uint8, otherData
 // Signature format is R and S integers in big-endian format. 64 bytes for P256
 // curve.
 func (d *Dev) sign(ctx context.Context, keyId uint16, msg []byte, sig []byte) (int, error) {
-	// make sure RNG has updated its seed
-	if _, err := d.random(ctx, nil); err != nil {
-		return 0, err
-	}
-
 	var (
 		target = nonceTargetTempKey
 		source = signSourceTempKey
@@ -217,10 +256,23 @@ func (d *Dev) sign(ctx context.Context, keyId uint16, msg []byte, sig []byte) (i
 		source = signSourceMsgDigBuf
 	}
 
-	if err := d.nonceLoad(ctx, target, msg); err != nil {
-		return 0, err
-	}
-	return d.signBase(ctx, signModeExternal, source, keyId, sig)
+	// The loaded message is lost if the device falls asleep in between.
+	var n int
+	err := d.KeepAwake(ctx, func(ctx context.Context) error {
+		// make sure RNG has updated its seed
+		if _, err := d.random(ctx, nil); err != nil {
+			return err
+		}
+
+		if err := d.nonceLoad(ctx, target, msg); err != nil {
+			return err
+		}
+
+		var err error
+		n, err = d.signBase(ctx, signModeExternal, source, keyId, sig)
+		return err
+	})
+	return n, err
 }
 
 // verifyExtern verifies a signature using external input.
@@ -245,18 +297,20 @@ func (d *Dev) verifyExtern(ctx context.Context, msg, sig, pub []byte) (bool, err
 		source = verifySourceMsgDigBuf
 	}
 
-	if err := d.nonceLoad(ctx, target, msg); err != nil {
-		return false, err
-	}
-
 	command, err := newVerifyCommand(
 		verifyModeExternal, source, verifyKeyP256, sig, pub, nil,
 	)
 	if err != nil {
 		return false, err
 	}
-	err = d.execute(ctx, command)
-	ok := err != errCRC
+
+	err = d.KeepAwake(ctx, func(ctx context.Context) error {
+		if err := d.nonceLoad(ctx, target, msg); err != nil {
+			return err
+		}
+		return d.execute(ctx, command)
+	})
+	ok := !errors.Is(err, ErrCRC)
 	return ok, err
 }
 
@@ -337,6 +391,9 @@ func (d *Dev) write(ctx context.Context, zone Zone, addr uint16, data []byte, ma
 }
 
 func (d *Dev) writeZone(ctx context.Context, zone Zone, slot uint16, block uint8, offset uint8, data []byte) error {
+	if d.cfg.DeviceType.isCA2() {
+		return d.writeZoneCA2(ctx, zone, slot, block, data)
+	}
 	if len(data) != atcaBlockSize && len(data) != atcaWordSize {
 		return errors.New("atecc: invalid write zone size")
 	}
@@ -354,77 +411,83 @@ func (d *Dev) writeZone(ctx context.Context, zone Zone, slot uint16, block uint8
 	return nil
 }
 
-// TODO: rewrite in idiomatic go
-func (d *Dev) writeBytesZone(ctx context.Context, zone Zone, slot uint16, offset uint8, data []byte) (int, error) {
-	if zone == ZoneData && slot > 15 {
-		return 0, errors.New("atecc: invalid slot")
-	}
-
-	// Always succeed reading 0 bytes
+// writeBytesZone writes the data to the zone, or to the data slot, starting at
+// offset.
+//
+// Offset and length must be multiples of the word size. Blocks are written
+// where the data is aligned to them, and words otherwise. The device only
+// accepts block writes to the data and OTP zones before the data zone is
+// locked, which callers must align to. A block extending past the end of a
+// data slot is written when the data ends with the slot, as the device ignores
+// the bytes past the end.
+func (d *Dev) writeBytesZone(ctx context.Context, zone Zone, slot uint16, offset int, data []byte) (int, error) {
+	if d.cfg.DeviceType.isCA2() {
+		return d.writeBytesZoneCA2(ctx, zone, slot, offset, data)
+	}
+
+	// Always succeed writing 0 bytes
 	if len(data) == 0 {
 		return 0, nil
 	}
 
-	// TODO: docs says it should be a multiple of 32?
-	if offset%atcaWordSize != 0 {
-		return 0, errors.New("atecc: invalid offset")
-	}
-	if len(data)%atcaWordSize != 0 {
-		return 0, errors.New("atecc: invalid length")
-	}
-
-	zoneSize, err := getZoneSize(zone, slot)
+	zoneSize, err := getZoneSize(d.cfg.DeviceType, zone, slot)
 	if err != nil {
 		return 0, err
 	}
-	if int(offset)+len(data) > zoneSize {
-		return 0, errors.New("atecc: invalid offset and zone")
+	if offset < 0 || offset+len(data) > zoneSize {
+		return 0, fmt.Errorf("atecc: write of %d bytes at offset %d is outside of the %d byte zone", len(data), offset, zoneSize)
+	}
+	if offset%atcaWordSize != 0 || len(data)%atcaWordSize != 0 {
+		return 0, fmt.Errorf("atecc: writes must be multiples of %d bytes", atcaWordSize)
 	}
 
-	block := offset / atcaBlockSize
-	word := (offset % atcaBlockSize) / atcaWordSize
+	var n int
+	for n < len(data) {
+		pos := offset + n
+		block, word := pos/atcaBlockSize, pos%atcaBlockSize/atcaWordSize
+		remaining := len(data) - n
 
-	var index = 0
-	for index < len(data) {
 		// Makes sure we skip writing to the selector, user extra, and lock bytes.
 		// These need to be written using the UpdateExtra command.
 		inLockBlock := zone == ZoneConfig && block == ateccconf.LockOffsetBlock
 		inLockWord := inLockBlock && word == ateccconf.LockOffsetWord
 
-		// Write block-wise when we're aligned and there's a full block available.
-		remaining := len(data) - index
-		writeBlock := word == 0 && remaining >= atcaBlockSize
-
-		if writeBlock && !inLockBlock {
-			err = d.writeZone(ctx, zone, slot, block, 0, data[index:index+atcaBlockSize])
-			if err != nil {
-				return index, err
-			}
-			index += atcaBlockSize
-			block += 1
-		} else {
-			if !inLockWord {
-				err = d.writeZone(ctx, zone, slot, block, word, data[index:index+atcaWordSize])
-				if err != nil {
-					return index, err
-				}
+		// Write block-wise when we're aligned and there's a full block, or the
+		// rest of the slot, available.
+		lastBlock := zone == ZoneData && pos+remaining == zoneSize
+		if word == 0 && !inLockBlock && (remaining >= atcaBlockSize || lastBlock) {
+			var buf [atcaBlockSize]byte
+			size := copy(buf[:], data[n:])
+			if err := d.writeZone(ctx, zone, slot, uint8(block), 0, buf[:]); err != nil {
+				return n, err
 			}
-			index += atcaWordSize
-			word += 1
-			if word == atcaBlockSize/atcaWordSize {
-				block += 1
-				word = 0
+			n += size
+			continue
+		}
+
+		if !inLockWord {
+			if err := d.writeZone(ctx, zone, slot, uint8(block), uint8(word), data[n:n+atcaWordSize]); err != nil {
+				return n, err
 			}
 		}
+		n += atcaWordSize
 	}
-	return index, nil
+	return n, nil
 }
 
 // TODO: rewrite in idiomatic go
 func (d *Dev) writeConfigZone(ctx context.Context, data []byte) (int, error) {
+	if d.cfg.DeviceType.isCA2() {
+		return d.writeConfigZoneCA2(ctx, data)
+	}
+
 	// Be very strict about the size. We don't want anyone to accidentally miss
 	// that this function actually skips the first 16 bytes, which is unexpected.
-	if zoneSizeConfig != len(data) {
+	zoneSize, err := getZoneSize(d.cfg.DeviceType, ZoneConfig, 0)
+	if err != nil {
+		return 0, err
+	}
+	if zoneSize != len(data) {
 		return 0, errors.New("atecc: config data size mismatch")
 	}
 
@@ -435,14 +498,64 @@ func (d *Dev) writeConfigZone(ctx context.Context, data []byte) (int, error) {
 		return n, err
 	}
 
-	// Write the UserExtra and UserExtraAdd. This may fail if either value is
-	// already non-zero.
+	// Write the UserExtra and UserExtraAdd, or Selector on devices prior to
+	// ATECC608. This may fail if either value is already non-zero.
 	if err := d.updateExtra(ctx, updateModeUserExtra, data[84]); err != nil {
 		return n, err
 	}
 	return n, d.updateExtra(ctx, updateModeUserExtraAdd, data[85])
 }
 
+// otpUsage returns how the OTP zone can be accessed, based on the OTP mode
+// and lock state in the config zone.
+func (d *Dev) otpUsage(ctx context.Context) (ateccconf.OTPUsage, error) {
+	if d.cfg.DeviceType.isCA2() {
+		return ateccconf.OTPUsage{}, errZoneNotSupported
+	}
+	config, err := d.ReadConfigZone(ctx)
+	if err != nil {
+		return ateccconf.OTPUsage{}, err
+	}
+
+	switch d.cfg.DeviceType {
+	case DeviceATECC508:
+		var conf ateccconf.Config508
+		if err := ateccconf.Unmarshal(config, &conf); err != nil {
+			return ateccconf.OTPUsage{}, err
+		}
+		return conf.OTPUsage(), nil
+	case DeviceATSHA204:
+		var conf ateccconf.Config204
+		if err := ateccconf.Unmarshal(config, &conf); err != nil {
+			return ateccconf.OTPUsage{}, err
+		}
+		return conf.OTPUsage(), nil
+	default:
+		var conf ateccconf.Config608
+		if err := ateccconf.Unmarshal(config, &conf); err != nil {
+			return ateccconf.OTPUsage{}, err
+		}
+		return conf.OTPUsage(), nil
+	}
+}
+
+// readOTPWords reads the OTP zone a word at a time.
+func (d *Dev) readOTPWords(ctx context.Context, offset int, data []byte) (int, error) {
+	var (
+		buf [atcaWordSize]byte
+		n   int
+	)
+	for n < len(data) {
+		pos := offset + n
+		block, word := pos/atcaBlockSize, pos%atcaBlockSize/atcaWordSize
+		if _, err := d.readZone(ctx, ZoneOTP, 0, uint8(block), uint8(word), buf[:]); err != nil {
+			return n, err
+		}
+		n += copy(data[n:], buf[pos%atcaWordSize:])
+	}
+	return n, nil
+}
+
 // updateExtra updates the two extra bytes within the configuration zone.
 //
 // This function executes the UpdateExtra command to update the values of the
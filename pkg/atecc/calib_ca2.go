@@ -0,0 +1,280 @@
+package atecc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+// The ECC204, SHA104, SHA105 and TA010 devices, referred to as CA2 devices by
+// Microchip, use a memory layout which differs from the earlier devices.
+//
+// The configuration zone consists of four subzones of 16 bytes, addressed
+// like slots. The data zone consists of four slots of varying size, accessed
+// in 32-byte pages. There is no OTP zone and the lock state is read using the
+// Info command rather than from the configuration.
+
+// Zone parameters of the CA2 devices.
+const (
+	ca2ZoneConfig uint8 = 0x00
+	ca2ZoneData   uint8 = 0x01
+)
+
+// Lock zones of the CA2 devices, the subzone or slot is given in param2.
+const (
+	lockZoneCA2Config = lockZone(0x00)
+	lockZoneCA2Data   = lockZone(0x01)
+)
+
+// infoModeLockStatus reads the lock status of a subzone or slot.
+const infoModeLockStatus infoMode = 0x02
+
+const (
+	zoneSizeConfigCA2 = ateccconf.ConfigSizeCA2
+	ca2SubzoneSize    = ateccconf.ConfigSubzoneSizeCA2
+	ca2PageSize       = atcaBlockSize
+	ca2SlotCount      = 4
+)
+
+// ca2SlotSizes holds the size of each data slot.
+var ca2SlotSizes = [ca2SlotCount]int{32, 320, 64, 32}
+
+// ca2ConfigSubzones are the subzones written and locked as the configuration
+// zone. Subzone 0 is locked by the factory and subzone 2 holds the monotonic
+// counter.
+var ca2ConfigSubzones = []uint16{1, 3}
+
+var errZoneNotSupported = errors.New("atecc: zone not supported by device")
+
+func getZoneSizeCA2(zone Zone, slot uint16) (int, error) {
+	switch zone {
+	case ZoneConfig:
+		return zoneSizeConfigCA2, nil
+	case ZoneData:
+		if slot >= ca2SlotCount {
+			return 0, errors.New("atecc: invalid slot received")
+		}
+		return ca2SlotSizes[slot], nil
+	default:
+		return 0, errZoneNotSupported
+	}
+}
+
+// ca2Zone returns the zone parameter and the size of each read or write.
+func ca2Zone(zone Zone) (uint8, int, error) {
+	switch zone {
+	case ZoneConfig:
+		return ca2ZoneConfig, ca2SubzoneSize, nil
+	case ZoneData:
+		return ca2ZoneData, ca2PageSize, nil
+	default:
+		return 0, 0, errZoneNotSupported
+	}
+}
+
+// ca2Addr computes the address of the config subzone or the data slot page.
+func ca2Addr(zone Zone, slot uint16, page uint8) uint16 {
+	if zone == ZoneConfig {
+		return slot
+	}
+	return slot | uint16(page)<<8
+}
+
+// readZoneCA2 reads a config subzone or a page of a data slot.
+//
+// The subzone is given by slot, data must be 16 bytes for the config zone and
+// 32 bytes for the data zone.
+func (d *Dev) readZoneCA2(ctx context.Context, zone Zone, slot uint16, page uint8, data []byte) (int, error) {
+	param1, size, err := ca2Zone(zone)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != size {
+		return 0, errors.New("atecc: invalid read zone size")
+	}
+
+	command, err := newPacket(atcaRead, param1, ca2Addr(zone, slot, page), nil)
+	if err != nil {
+		return 0, err
+	}
+	return d.executeResponse(ctx, command, data)
+}
+
+// writeZoneCA2 writes a config subzone or a page of a data slot.
+func (d *Dev) writeZoneCA2(ctx context.Context, zone Zone, slot uint16, page uint8, data []byte) error {
+	param1, size, err := ca2Zone(zone)
+	if err != nil {
+		return err
+	}
+	if len(data) != size {
+		return errors.New("atecc: invalid write zone size")
+	}
+
+	command, err := newPacket(atcaWrite, param1, ca2Addr(zone, slot, page), data)
+	if err != nil {
+		return err
+	}
+	return d.execute(ctx, command)
+}
+
+// ca2Position returns the slot and page holding the byte offset.
+func ca2Position(zone Zone, slot uint16, offset int) (uint16, uint8) {
+	if zone == ZoneConfig {
+		return uint16(offset / ca2SubzoneSize), 0
+	}
+	return slot, uint8(offset / ca2PageSize)
+}
+
+func (d *Dev) readBytesZoneCA2(ctx context.Context, zone Zone, slot uint16, offset int, data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	_, size, err := ca2Zone(zone)
+	if err != nil {
+		return 0, err
+	}
+	zoneSize, err := getZoneSizeCA2(zone, slot)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 || offset+len(data) > zoneSize {
+		return 0, errors.New("atecc: invalid offset and zone")
+	}
+
+	var (
+		buf [ca2PageSize]byte
+		n   int
+	)
+	for n < len(data) {
+		pos := offset + n
+		s, page := ca2Position(zone, slot, pos)
+		if _, err := d.readZoneCA2(ctx, zone, s, page, buf[:size]); err != nil {
+			return n, err
+		}
+		n += copy(data[n:], buf[pos%size:size])
+	}
+	return n, nil
+}
+
+func (d *Dev) writeBytesZoneCA2(ctx context.Context, zone Zone, slot uint16, offset int, data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	_, size, err := ca2Zone(zone)
+	if err != nil {
+		return 0, err
+	}
+	if offset%size != 0 {
+		return 0, errors.New("atecc: invalid offset")
+	}
+	if len(data)%size != 0 {
+		return 0, errors.New("atecc: invalid length")
+	}
+	zoneSize, err := getZoneSizeCA2(zone, slot)
+	if err != nil {
+		return 0, err
+	}
+	if offset+len(data) > zoneSize {
+		return 0, errors.New("atecc: invalid offset and zone")
+	}
+
+	for n := 0; n < len(data); n += size {
+		s, page := ca2Position(zone, slot, offset+n)
+		if err := d.writeZoneCA2(ctx, zone, s, page, data[n:n+size]); err != nil {
+			return n, err
+		}
+	}
+	return len(data), nil
+}
+
+// writeConfigZoneCA2 writes the writable subzones of the configuration.
+func (d *Dev) writeConfigZoneCA2(ctx context.Context, data []byte) (int, error) {
+	if len(data) != zoneSizeConfigCA2 {
+		return 0, errors.New("atecc: config data size mismatch")
+	}
+
+	var n int
+	for _, subzone := range ca2ConfigSubzones {
+		offset := int(subzone) * ca2SubzoneSize
+		if err := d.writeZoneCA2(ctx, ZoneConfig, subzone, 0, data[offset:offset+ca2SubzoneSize]); err != nil {
+			return n, err
+		}
+		n += ca2SubzoneSize
+	}
+	return n, nil
+}
+
+func (d *Dev) lockCA2(ctx context.Context, zone lockZone, slot uint16) error {
+	command, err := newPacket(atcaLock, uint8(zone), slot, nil)
+	if err != nil {
+		return err
+	}
+	return d.execute(ctx, command)
+}
+
+func (d *Dev) lockConfigZoneCA2(ctx context.Context) error {
+	for _, subzone := range ca2ConfigSubzones {
+		if err := d.lockCA2(ctx, lockZoneCA2Config, subzone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dev) lockDataZoneCA2(ctx context.Context) error {
+	for slot := uint16(0); slot < ca2SlotCount; slot++ {
+		if err := d.lockCA2(ctx, lockZoneCA2Data, slot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSlotLockedCA2 returns true if the config subzone or data slot is locked.
+//
+// The lock status is returned in the first byte of the Info response.
+func (d *Dev) isSlotLockedCA2(ctx context.Context, zone Zone, slot uint16) (bool, error) {
+	param, _, err := ca2Zone(zone)
+	if err != nil {
+		return false, err
+	}
+
+	command, err := newPacket(atcaInfo, uint8(infoModeLockStatus), slot<<1|uint16(param), nil)
+	if err != nil {
+		return false, err
+	}
+	var recv [4]byte
+	n, err := d.executeResponse(ctx, command, recv[:])
+	if err != nil {
+		return false, err
+	} else if n != len(recv) {
+		return false, errors.New("atecc: unexpected lock status size")
+	}
+	return recv[0] != 0, nil
+}
+
+// isLockedCA2 returns true if all subzones or slots of the zone are locked.
+func (d *Dev) isLockedCA2(ctx context.Context, zone Zone) (bool, error) {
+	var slots []uint16
+	switch zone {
+	case ZoneConfig:
+		slots = ca2ConfigSubzones
+	case ZoneData:
+		for slot := uint16(0); slot < ca2SlotCount; slot++ {
+			slots = append(slots, slot)
+		}
+	default:
+		return false, errors.New("atecc: unknown lock zone")
+	}
+
+	for _, slot := range slots {
+		locked, err := d.isSlotLockedCA2(ctx, zone, slot)
+		if err != nil || !locked {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,180 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+// simCA2 emulates the memory layout of an ECC204.
+//
+// Commands not touching the memory are executed by the simDevice.
+type simCA2 struct {
+	config       [zoneSizeConfigCA2]byte
+	data         [ca2SlotCount][]byte
+	configLocked [zoneSizeConfigCA2 / ca2SubzoneSize]bool
+	slotLocked   [ca2SlotCount]bool
+}
+
+func newSimCA2Device() *simDevice {
+	s := newSimDevice()
+	s.revision = [4]byte{0x00, 0x02, deviceIDECC204, 0x01}
+	s.ca2 = &simCA2{}
+	copy(s.ca2.config[:], s.config[:16])
+	copy(s.ca2.config[4:], s.revision[:])
+	s.ca2.configLocked[0] = true
+	for i := range s.ca2.data {
+		s.ca2.data[i] = make([]byte, ca2SlotSizes[i])
+	}
+	return s
+}
+
+func (c *simCA2) execute(opcode, param1 uint8, param2 uint16, data []byte) (byte, []byte, bool) {
+	switch opcode {
+	case atcaInfo:
+		if infoMode(param1) != infoModeLockStatus {
+			return 0, nil, false
+		}
+		// the status is returned in the first of four bytes
+		slot := int(param2>>1) % 4
+		if param2&0x01 == uint16(ca2ZoneConfig) {
+			return simStatusOK, []byte{boolByte(c.configLocked[slot]), 0, 0, 0}, true
+		}
+		return simStatusOK, []byte{boolByte(c.slotLocked[slot]), 0, 0, 0}, true
+	case atcaRead:
+		buf, _, ok := c.buffer(param1, param2)
+		if !ok {
+			return simStatusParse, nil, true
+		} else if param1 == ca2ZoneData && param2&0xff == 0 {
+			// the private key can not be read
+			return simStatusExecution, nil, true
+		}
+		return simStatusOK, buf, true
+	case atcaWrite:
+		buf, locked, ok := c.buffer(param1, param2)
+		if !ok || len(data) != len(buf) {
+			return simStatusParse, nil, true
+		} else if locked {
+			return simStatusExecution, nil, true
+		}
+		copy(buf, data)
+		return simStatusOK, nil, true
+	case atcaLock:
+		slot := int(param2)
+		if slot >= 4 {
+			return simStatusParse, nil, true
+		}
+		locked := &c.slotLocked[slot]
+		if lockZone(param1) == lockZoneCA2Config {
+			locked = &c.configLocked[slot]
+		}
+		if *locked {
+			return simStatusExecution, nil, true
+		}
+		*locked = true
+		return simStatusOK, nil, true
+	default:
+		return 0, nil, false
+	}
+}
+
+// buffer returns the memory addressed by the command and its lock state.
+func (c *simCA2) buffer(param1 uint8, param2 uint16) ([]byte, bool, bool) {
+	switch param1 {
+	case ca2ZoneConfig:
+		subzone := int(param2)
+		if subzone >= len(c.configLocked) {
+			return nil, false, false
+		}
+		offset := subzone * ca2SubzoneSize
+		return c.config[offset : offset+ca2SubzoneSize], c.configLocked[subzone], true
+	case ca2ZoneData:
+		slot, page := int(param2&0xff), int(param2>>8)
+		if slot >= ca2SlotCount || (page+1)*ca2PageSize > len(c.data[slot]) {
+			return nil, false, false
+		}
+		offset := page * ca2PageSize
+		return c.data[slot][offset : offset+ca2PageSize], c.slotLocked[slot], true
+	default:
+		return nil, false, false
+	}
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestCA2Dev(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimCA2Device()
+	d, err := New(ctx, sim, IfaceConfig{DeviceType: DeviceECC204})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sn, err := d.SerialNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(sn, simSerialNumber) {
+		t.Errorf("got %x, want %x", sn, simSerialNumber)
+	}
+
+	conf, err := d.ReadConfigZone(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(conf, sim.ca2.config[:]) {
+		t.Errorf("got %x, want %x", conf, sim.ca2.config)
+	}
+
+	// write pages of the data slot and read them back
+	want := bytes.Repeat([]byte{0xab, 0xcd}, ca2PageSize)
+	if err := d.WriteBytesZone(ctx, ZoneData, 1, ca2PageSize, want); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(want))
+	if _, err := d.readBytesZone(ctx, ZoneData, 1, ca2PageSize, got); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+
+	if err := d.WriteBytesZone(ctx, ZoneOTP, 0, 0, make([]byte, 32)); !errors.Is(err, errZoneNotSupported) {
+		t.Errorf("got %v, want %v", err, errZoneNotSupported)
+	}
+
+	pub, err := d.GenerateKey(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("hello"))
+	sig, err := d.Sign(ctx, 0, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], sig) {
+		t.Error("signature verification failed")
+	}
+
+	// the lock state is read using the info command
+	if err := d.LockConfigZone(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if locked, err := d.IsConfigZoneLocked(ctx); err != nil || !locked {
+		t.Errorf("config zone locked: %v, %v", locked, err)
+	}
+	if err := d.LockDataSlot(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if locked, err := d.IsDataZoneLocked(ctx); err != nil || locked {
+		t.Errorf("data zone locked: %v, %v", locked, err)
+	}
+	if err := d.WriteBytesZone(ctx, ZoneData, 1, 0, want[:ca2PageSize]); !errors.Is(err, ErrExecution) {
+		t.Errorf("got %v, want %v", err, ErrExecution)
+	}
+}
@@ -1,6 +1,9 @@
 package atecc
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // General device command opcodes
 //nolint unused commands
@@ -32,6 +35,64 @@ const (
 	atcaSelfTest    = 0x77 // Self test command op-code
 )
 
+// opcodeName returns the command name of the opcode.
+func opcodeName(opcode uint8) string {
+	switch opcode {
+	case atcaCheckMac:
+		return "CheckMac"
+	case atcaDeriveKey:
+		return "DeriveKey"
+	case atcaInfo:
+		return "Info"
+	case atcaGenDig:
+		return "GenDig"
+	case atcaGenKey:
+		return "GenKey"
+	case atcaHMAC:
+		return "HMAC"
+	case atcaLock:
+		return "Lock"
+	case atcaMAC:
+		return "MAC"
+	case atcaNonce:
+		return "Nonce"
+	case atcaPause:
+		return "Pause"
+	case atcaPrivWrite:
+		return "PrivWrite"
+	case atcaRandom:
+		return "Random"
+	case atcaRead:
+		return "Read"
+	case atcaSign:
+		return "Sign"
+	case atcaUpdateExtra:
+		return "UpdateExtra"
+	case atcaVerify:
+		return "Verify"
+	case atcaWrite:
+		return "Write"
+	case atcaECDH:
+		return "ECDH"
+	case atcaCounter:
+		return "Counter"
+	case atcaDelete:
+		return "Delete"
+	case atcaSHA:
+		return "SHA"
+	case atcaAES:
+		return "AES"
+	case atcaKDF:
+		return "KDF"
+	case atcaSecureBoot:
+		return "SecureBoot"
+	case atcaSelfTest:
+		return "SelfTest"
+	default:
+		return fmt.Sprintf("0x%02x", opcode)
+	}
+}
+
 type infoMode uint8
 
 const (
@@ -231,6 +292,26 @@ func newWriteCommand(zone Zone, addr uint16, value []byte, mac []byte) (*packet,
 	return newPacket(atcaWrite, param1, addr, data[:])
 }
 
+type privWriteMode uint8
+
+//nolint unused
+const (
+	privWriteModeClear     privWriteMode = 0x00
+	privWriteModeEncrypted privWriteMode = 0x40
+)
+
+// newPrivWriteCommand writes a private key, given as 4 zero bytes followed by
+// the 32 byte scalar, together with an optional mac.
+func newPrivWriteCommand(mode privWriteMode, keyId uint8, value []byte, mac []byte) (*packet, error) {
+	var data [36 + 32]byte
+	if len(value) != 36 {
+		return nil, errors.New("atecc: private key must be 36 bytes")
+	}
+	copy(data[:], value)
+	copy(data[36:], mac)
+	return newPacket(atcaPrivWrite, uint8(mode), uint16(keyId), data[:])
+}
+
 type updateMode uint8
 
 const (
@@ -2,6 +2,7 @@ package atecc
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/northvolt/go-atecc/pkg/ateccconf"
@@ -12,25 +13,158 @@ type DeviceType int
 
 const (
 	DeviceATECC608 DeviceType = iota
+	DeviceATECC508
+	DeviceATSHA204
+	DeviceECC204
+	DeviceSHA104
+	DeviceSHA105
+	DeviceTA010
 )
 
+// ErrUnsupportedCommand is returned when a command is not supported by the
+// device type.
+var ErrUnsupportedCommand = errors.New("atecc: command not supported by device")
+
 func (dt DeviceType) String() string {
 	switch dt {
 	case DeviceATECC608:
 		return "ATECC608"
+	case DeviceATECC508:
+		return "ATECC508"
+	case DeviceATSHA204:
+		return "ATSHA204"
+	case DeviceECC204:
+		return "ECC204"
+	case DeviceSHA104:
+		return "SHA104"
+	case DeviceSHA105:
+		return "SHA105"
+	case DeviceTA010:
+		return "TA010"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrDeviceTypeMismatch is returned when the detected device type differs from
+// the configured one and IfaceConfig.StrictDeviceType is set.
+var ErrDeviceTypeMismatch = errors.New("atecc: device type mismatch")
+
+// Variant is the silicon variant or provisioning of a device.
+type Variant int
+
+const (
+	VariantUnknown Variant = iota
+	VariantATECC608A
+	VariantATECC608B
+	// VariantTNG is an ATECC608 pre-provisioned as Trust&GO.
+	VariantTNG
+	// VariantTFLX is an ATECC608 pre-provisioned as TrustFLEX.
+	VariantTFLX
+)
+
+func (v Variant) String() string {
+	switch v {
+	case VariantATECC608A:
+		return "ATECC608A"
+	case VariantATECC608B:
+		return "ATECC608B"
+	case VariantTNG:
+		return "ATECC608-TNGTLS"
+	case VariantTFLX:
+		return "ATECC608-TFLXTLS"
 	default:
 		return "unknown"
 	}
 }
 
+// I²C addresses programmed by Microchip into pre-provisioned devices.
+const (
+	variantAddressTNG  = 0x6a
+	variantAddressTFLX = 0x6c
+)
+
+// variantFromInfo returns the variant based on the revision and the first
+// block of the config zone.
+//
+// Pre-provisioned devices are recognized by the I²C address programmed by
+// Microchip, which makes it a best effort guess as the same address may be
+// used in a custom configuration.
+func variantFromInfo(dt DeviceType, revision []byte, config []byte) Variant {
+	if dt != DeviceATECC608 || len(revision) < 4 {
+		return VariantUnknown
+	}
+	if len(config) > 16 {
+		switch config[16] {
+		case variantAddressTNG:
+			return VariantTNG
+		case variantAddressTFLX:
+			return VariantTFLX
+		}
+	}
+	switch revision[3] {
+	case 0x02:
+		return VariantATECC608A
+	case 0x03:
+		return VariantATECC608B
+	default:
+		return VariantUnknown
+	}
+}
+
+// isCA2 returns true for the ECC204 family of devices.
+//
+// These devices use a slot based configuration zone and 32-byte pages in the
+// data zone, see calib_ca2.go.
+func (dt DeviceType) isCA2() bool {
+	switch dt {
+	case DeviceECC204, DeviceSHA104, DeviceSHA105, DeviceTA010:
+		return true
+	default:
+		return false
+	}
+}
+
+// Device IDs of the ECC204 family, found in the third revision byte.
+const (
+	deviceIDECC204 = 0x5a
+	deviceIDSHA104 = 0x5b
+	deviceIDSHA105 = 0x5c
+	deviceIDTA010  = 0x5d
+)
+
 // DeviceTypeFromInfo returns the device type based on the info byte array.
 func DeviceTypeFromInfo(revision []byte) (DeviceType, error) {
 	if len(revision) < 3 {
 		return 0, errors.New("atecc: device type revision too small")
 	}
+
+	// The ECC204 family is identified by the second byte.
+	if revision[1] == 0x02 {
+		switch revision[2] {
+		case deviceIDECC204:
+			return DeviceECC204, nil
+		case deviceIDSHA104:
+			return DeviceSHA104, nil
+		case deviceIDSHA105:
+			return DeviceSHA105, nil
+		case deviceIDTA010:
+			return DeviceTA010, nil
+		default:
+			return 0, errors.New("attec: unknown device revision")
+		}
+	}
+
 	switch revision[2] {
 	case 0x60:
 		return DeviceATECC608, nil
+	case 0x50:
+		return DeviceATECC508, nil
+	case 0x00:
+		if revision[1] == 0x00 {
+			return DeviceATSHA204, nil
+		}
+		return 0, errors.New("attec: unknown device revision")
 	default:
 		return 0, errors.New("attec: unknown device revision")
 	}
@@ -40,6 +174,10 @@ const (
 	deviceExecutionTime608M0 = iota
 	deviceExecutionTime608M1
 	deviceExecutionTime608M2
+	deviceExecutionTime508
+	deviceExecutionTime204
+	deviceExecutionTimeECC204
+	deviceExecutionTimeSHA104
 )
 
 // deviceExecutionTimes holds execution times for device supported commands.
@@ -120,8 +258,84 @@ var deviceExecutionTimes = []map[uint8]time.Duration{
 		atcaVerify:      1085 * time.Millisecond,
 		atcaWrite:       45 * time.Millisecond,
 	},
+	// ATECC508
+	{
+		atcaCheckMac:    13 * time.Millisecond,
+		atcaCounter:     20 * time.Millisecond,
+		atcaDeriveKey:   50 * time.Millisecond,
+		atcaECDH:        58 * time.Millisecond,
+		atcaGenDig:      11 * time.Millisecond,
+		atcaGenKey:      115 * time.Millisecond,
+		atcaHMAC:        23 * time.Millisecond,
+		atcaInfo:        2 * time.Millisecond,
+		atcaLock:        32 * time.Millisecond,
+		atcaMAC:         14 * time.Millisecond,
+		atcaNonce:       29 * time.Millisecond,
+		atcaPause:       3 * time.Millisecond,
+		atcaPrivWrite:   48 * time.Millisecond,
+		atcaRandom:      23 * time.Millisecond,
+		atcaRead:        2 * time.Millisecond,
+		atcaSHA:         9 * time.Millisecond,
+		atcaSign:        60 * time.Millisecond,
+		atcaUpdateExtra: 10 * time.Millisecond,
+		atcaVerify:      72 * time.Millisecond,
+		atcaWrite:       26 * time.Millisecond,
+	},
+	// ATSHA204
+	{
+		atcaCheckMac:    38 * time.Millisecond,
+		atcaDeriveKey:   62 * time.Millisecond,
+		atcaGenDig:      43 * time.Millisecond,
+		atcaHMAC:        69 * time.Millisecond,
+		atcaInfo:        2 * time.Millisecond,
+		atcaLock:        24 * time.Millisecond,
+		atcaMAC:         35 * time.Millisecond,
+		atcaNonce:       60 * time.Millisecond,
+		atcaPause:       2 * time.Millisecond,
+		atcaRandom:      50 * time.Millisecond,
+		atcaRead:        5 * time.Millisecond,
+		atcaSHA:         22 * time.Millisecond,
+		atcaUpdateExtra: 12 * time.Millisecond,
+		atcaWrite:       42 * time.Millisecond,
+	},
+	// ECC204 and TA010
+	{
+		atcaCounter:  20 * time.Millisecond,
+		atcaDelete:   200 * time.Millisecond,
+		atcaGenKey:   500 * time.Millisecond,
+		atcaInfo:     20 * time.Millisecond,
+		atcaLock:     80 * time.Millisecond,
+		atcaNonce:    20 * time.Millisecond,
+		atcaRandom:   40 * time.Millisecond,
+		atcaRead:     40 * time.Millisecond,
+		atcaSelfTest: 600 * time.Millisecond,
+		atcaSHA:      80 * time.Millisecond,
+		atcaSign:     500 * time.Millisecond,
+		atcaWrite:    40 * time.Millisecond,
+	},
+	// SHA104 and SHA105
+	{
+		atcaCheckMac: 40 * time.Millisecond,
+		atcaCounter:  20 * time.Millisecond,
+		atcaDelete:   200 * time.Millisecond,
+		atcaGenDig:   20 * time.Millisecond,
+		atcaInfo:     20 * time.Millisecond,
+		atcaLock:     80 * time.Millisecond,
+		atcaMAC:      40 * time.Millisecond,
+		atcaNonce:    20 * time.Millisecond,
+		atcaRead:     40 * time.Millisecond,
+		atcaSelfTest: 600 * time.Millisecond,
+		atcaSHA:      80 * time.Millisecond,
+		atcaWrite:    40 * time.Millisecond,
+	},
 }
 
+// getDeviceExecutionTime returns the execution times of the device.
+//
+// The ATECC608 variants share the timing tables, as do the datasheets and
+// cryptoauthlib for ATECC608A and ATECC608B. Their execution times only depend
+// on the clock divider, and the Trust&GO and TrustFLEX variants are ATECC608B
+// devices with a provisioned configuration.
 func getDeviceExecutionTime(dt DeviceType, div ateccconf.ClockDivider) (map[uint8]time.Duration, error) {
 	switch dt {
 	case DeviceATECC608:
@@ -135,19 +349,40 @@ func getDeviceExecutionTime(dt DeviceType, div ateccconf.ClockDivider) (map[uint
 		default:
 			return nil, errors.New("atecc: unknown clock divider")
 		}
+	case DeviceATECC508:
+		return deviceExecutionTimes[deviceExecutionTime508], nil
+	case DeviceATSHA204:
+		return deviceExecutionTimes[deviceExecutionTime204], nil
+	case DeviceECC204, DeviceTA010:
+		return deviceExecutionTimes[deviceExecutionTimeECC204], nil
+	case DeviceSHA104, DeviceSHA105:
+		return deviceExecutionTimes[deviceExecutionTimeSHA104], nil
 	default:
 		return nil, errors.New("atecc: unknown execution time for device")
 	}
 }
 
+// getMaxExecutionTime returns the longest execution time of the opcode on any
+// device, used before the device type is known.
+func getMaxExecutionTime(opcode uint8) time.Duration {
+	var max time.Duration
+	for _, executionTimes := range deviceExecutionTimes {
+		if t := executionTimes[opcode]; t > max {
+			max = t
+		}
+	}
+	return max
+}
+
 func getExecutionTime(dt DeviceType, div ateccconf.ClockDivider, opcode uint8) (time.Duration, error) {
 	executionTimes, err := getDeviceExecutionTime(dt, div)
 	if err != nil {
 		return 0, err
 	}
 
+	// Commands without an execution time are not supported by the device.
 	if t, ok := executionTimes[opcode]; !ok {
-		return 0, errors.New("atecc: unknown execution time for op")
+		return 0, fmt.Errorf("%w: %s on %s", ErrUnsupportedCommand, opcodeName(opcode), dt)
 	} else {
 		return t, nil
 	}
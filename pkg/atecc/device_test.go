@@ -0,0 +1,99 @@
+package atecc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDeviceTypeFromInfo(t *testing.T) {
+	testCases := []struct {
+		revision []byte
+		want     DeviceType
+	}{
+		{[]byte{0x00, 0x00, 0x60, 0x02}, DeviceATECC608},
+		{[]byte{0x00, 0x00, 0x50, 0x00}, DeviceATECC508},
+		{[]byte{0x00, 0x00, 0x00, 0x09}, DeviceATSHA204},
+		{[]byte{0x00, 0x02, deviceIDECC204, 0x01}, DeviceECC204},
+		{[]byte{0x00, 0x02, deviceIDTA010, 0x01}, DeviceTA010},
+	}
+	for _, tc := range testCases {
+		got, err := DeviceTypeFromInfo(tc.revision)
+		if err != nil {
+			t.Errorf("%x: %v", tc.revision, err)
+		} else if got != tc.want {
+			t.Errorf("%x: got %s, want %s", tc.revision, got, tc.want)
+		}
+	}
+
+	if _, err := DeviceTypeFromInfo([]byte{0x00, 0x00, 0x99, 0x00}); err == nil {
+		t.Error("expected error for unknown revision")
+	}
+}
+
+func TestUnsupportedCommand(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.revision = [4]byte{0x00, 0x00, 0x00, 0x09}
+	d, err := New(ctx, sim, IfaceConfig{DeviceType: DeviceATSHA204})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := d.ReadConfigZone(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(conf) != zoneSizeConfig204 {
+		t.Errorf("got %d bytes, want %d", len(conf), zoneSizeConfig204)
+	}
+
+	sim.opcodes = nil
+	if _, err := d.GenerateKey(ctx, 0); !errors.Is(err, ErrUnsupportedCommand) {
+		t.Errorf("got %v, want %v", err, ErrUnsupportedCommand)
+	}
+	if len(sim.opcodes) != 0 {
+		t.Errorf("unsupported command sent to device: %x", sim.opcodes)
+	}
+}
+
+func TestDetectDeviceType(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name     string
+		revision [4]byte
+		address  byte
+		want     Variant
+	}{
+		{"608A", [4]byte{0x00, 0x00, 0x60, 0x02}, 0xc0, VariantATECC608A},
+		{"608B", [4]byte{0x00, 0x00, 0x60, 0x03}, 0xc0, VariantATECC608B},
+		{"TNG", [4]byte{0x00, 0x00, 0x60, 0x03}, variantAddressTNG, VariantTNG},
+		{"TFLX", [4]byte{0x00, 0x00, 0x60, 0x03}, variantAddressTFLX, VariantTFLX},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := newSimDevice()
+			sim.revision = tc.revision
+			sim.config[16] = tc.address
+
+			// the detected type replaces the configured one
+			d, err := New(ctx, sim, IfaceConfig{DeviceType: DeviceATECC508})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.DeviceType(); got != DeviceATECC608 {
+				t.Errorf("got %s, want %s", got, DeviceATECC608)
+			}
+			if got := d.Variant(); got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		cfg := IfaceConfig{DeviceType: DeviceATECC508, StrictDeviceType: true}
+		_, err := New(ctx, newSimDevice(), cfg)
+		if !errors.Is(err, ErrDeviceTypeMismatch) {
+			t.Errorf("got %v, want %v", err, ErrDeviceTypeMismatch)
+		}
+	})
+}
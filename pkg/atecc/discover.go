@@ -0,0 +1,174 @@
+package atecc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// DiscoverOptions configures which interfaces Discover probes.
+type DiscoverOptions struct {
+	// I2CBuses are scanned for devices on all addresses.
+	//
+	// The buses are owned by the caller and left open.
+	I2CBuses []i2c.Bus
+
+	// HID enables probing of all HID kits.
+	//
+	// HID kits are skipped if USB support is missing.
+	HID bool
+
+	// Debug is used for debug output.
+	Debug Logger
+}
+
+// DeviceDescriptor describes a device found by Discover.
+type DeviceDescriptor struct {
+	// IfaceType is the interface the device was found on.
+	IfaceType IfaceType
+	// Bus identifies the I²C bus or the kit the device is connected to.
+	Bus string
+	// Address is the I²C target address or, for kits, the device identity.
+	Address uint16
+	// DeviceType is the type of the device.
+	DeviceType DeviceType
+	// Variant is the silicon variant of the device.
+	Variant Variant
+	// Revision is the device revision, as returned by Info.
+	Revision []byte
+	// SerialNumber is the 9 byte serial number of the device.
+	SerialNumber []byte
+	// ConfigZoneLocked is set if the config zone is locked.
+	ConfigZoneLocked bool
+	// DataZoneLocked is set if the data zone is locked.
+	DataZoneLocked bool
+}
+
+// Discover enumerates all reachable devices.
+//
+// Each device found is woken up, described and put back to sleep. All devices
+// found are returned together with any error encountered while probing, which
+// means that the list might be incomplete if err is non-nil.
+func Discover(ctx context.Context, opts DiscoverOptions) ([]DeviceDescriptor, error) {
+	var (
+		descs []DeviceDescriptor
+		errs  []error
+	)
+	for _, bus := range opts.I2CBuses {
+		cfg := ConfigATECCX08A_I2CDefault(bus)
+		cfg.Debug = opts.Debug
+		found, err := discoverI2C(ctx, cfg)
+		descs = append(descs, found...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", bus, err))
+		}
+	}
+
+	if opts.HID {
+		cfg := ConfigATECCX08A_KitHIDDefault()
+		cfg.Debug = opts.Debug
+		found, err := discoverHID(ctx, cfg)
+		descs = append(descs, found...)
+		if err != nil && !errors.Is(err, ErrUSBNotSupported) {
+			errs = append(errs, err)
+		}
+	}
+
+	return descs, errors.Join(errs...)
+}
+
+func discoverI2C(ctx context.Context, cfg IfaceConfig) ([]DeviceDescriptor, error) {
+	// The bus manager must not be closed, the bus is owned by the caller.
+	b := NewBus(cfg)
+	devs, err := b.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		descs []DeviceDescriptor
+		errs  []error
+	)
+	for _, d := range devs {
+		desc, err := describe(ctx, d)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("0x%02x: %w", d.cfg.I2C.Address, err))
+		} else {
+			desc.Bus = cfg.I2C.Bus.String()
+			desc.Address = d.cfg.I2C.Address
+			descs = append(descs, desc)
+		}
+		_ = d.Close()
+	}
+	return descs, errors.Join(errs...)
+}
+
+// discoverKit returns all devices connected to the kit.
+//
+// The kit transport is left open.
+func discoverKit(ctx context.Context, phy io.ReadWriter, cfg IfaceConfig, name string) ([]DeviceDescriptor, error) {
+	kit := newHALKitUnselected(phy, nil, cfg)
+	kitDevs, err := kit.devices()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		descs []DeviceDescriptor
+		errs  []error
+	)
+	for _, kd := range kitDevs {
+		desc, err := discoverKitDevice(ctx, kit, kd)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%02X: %w", kd.Address, err))
+			continue
+		}
+		desc.Bus = name
+		descs = append(descs, desc)
+	}
+	return descs, errors.Join(errs...)
+}
+
+func discoverKitDevice(ctx context.Context, kit *halKit, kd kitDevice) (DeviceDescriptor, error) {
+	kit.cfg.DeviceType = kd.DeviceType
+	if err := kit.selectDevice(kd.Address); err != nil {
+		return DeviceDescriptor{}, err
+	}
+
+	d, err := New(ctx, kit, kit.cfg)
+	if err != nil {
+		return DeviceDescriptor{}, err
+	}
+	defer d.Close()
+
+	desc, err := describe(ctx, d)
+	desc.Address = uint16(kd.Address)
+	return desc, err
+}
+
+// describe returns the descriptor of the device.
+func describe(ctx context.Context, d *Dev) (DeviceDescriptor, error) {
+	desc := DeviceDescriptor{
+		IfaceType:  d.cfg.IfaceType,
+		DeviceType: d.DeviceType(),
+		Variant:    d.Variant(),
+	}
+
+	var err error
+	if desc.Revision, err = d.Revision(ctx); err != nil {
+		return desc, err
+	}
+	if desc.SerialNumber, err = d.SerialNumber(ctx); err != nil {
+		return desc, err
+	}
+	if desc.ConfigZoneLocked, err = d.IsConfigZoneLocked(ctx); err != nil {
+		return desc, err
+	}
+	if desc.DataZoneLocked, err = d.IsDataZoneLocked(ctx); err != nil {
+		return desc, err
+	}
+	return desc, nil
+}
@@ -0,0 +1,76 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+func TestDiscover(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeI2CBus(0x60, 0x35)
+	bus.sims[0x35].lock()
+	for _, sim := range bus.sims {
+		sim.config[0] = 0x42 // distinguish the serial numbers
+	}
+
+	descs, err := Discover(ctx, DiscoverOptions{I2CBuses: []i2c.Bus{bus}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descs) != 2 {
+		t.Fatalf("got %d devices, want 2", len(descs))
+	}
+
+	for i, addr := range []uint16{0x35, 0x60} {
+		desc := descs[i]
+		if desc.IfaceType != IfaceI2C || desc.Bus != "fake" || desc.Address != addr {
+			t.Errorf("%d: got %v %s 0x%02x", i, desc.IfaceType, desc.Bus, desc.Address)
+		}
+		if !bytes.Equal(desc.Revision, bus.sims[addr].revision[:]) {
+			t.Errorf("%d: got revision %x", i, desc.Revision)
+		}
+		if desc.SerialNumber[0] != 0x42 {
+			t.Errorf("%d: got serial number %x", i, desc.SerialNumber)
+		}
+		locked := addr == 0x35
+		if desc.ConfigZoneLocked != locked || desc.DataZoneLocked != locked {
+			t.Errorf("%d: got locks %v %v, want %v", i, desc.ConfigZoneLocked, desc.DataZoneLocked, locked)
+		}
+	}
+	for addr, sim := range bus.sims {
+		if sim.awake {
+			t.Errorf("0x%02x: device left awake", addr)
+		}
+	}
+	if bus.closed {
+		t.Error("bus closed")
+	}
+}
+
+func TestDiscoverKit(t *testing.T) {
+	ctx := context.Background()
+	cfg := ConfigATECCX08A_KitHIDDefault()
+	sim := newSimDevice()
+	kit := &kitBoard{sim: sim, packetSize: getPacketSize(cfg)}
+
+	descs, err := discoverKit(ctx, kit, cfg, "kit0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descs) != 1 {
+		t.Fatalf("got %d devices, want 1", len(descs))
+	}
+	desc := descs[0]
+	if desc.IfaceType != IfaceHID || desc.Bus != "kit0" || desc.Address != 0xc0 {
+		t.Errorf("got %v %s 0x%02x", desc.IfaceType, desc.Bus, desc.Address)
+	}
+	if !bytes.Equal(desc.SerialNumber, simSerialNumber) {
+		t.Errorf("got %x, want %x", desc.SerialNumber, simSerialNumber)
+	}
+	if sim.awake {
+		t.Error("device left awake")
+	}
+}
@@ -1,36 +1,58 @@
 package atecc
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 )
 
 // Protocol errors. See datasheet for specification.
+//
+// The errors are returned wrapped in a *StatusError when the device responds
+// with a status code. Use errors.Is to check for a specific error.
 var (
-	errCheckMacVerifyFailed = errors.New("atecc: check mac verify failed")
+	// ErrCheckMacVerifyFailed is used when a CheckMac or Verify miscompares.
+	ErrCheckMacVerifyFailed = errors.New("atecc: check mac verify failed")
 
-	// errParseError is used when protocol was not understood.
+	// ErrParseError is used when protocol was not understood.
 	//
 	// Received length, op-code or any parameter was illegal.
-	errParseError = errors.New("atecc: protocol error")
+	ErrParseError = errors.New("atecc: protocol error")
+
+	ErrProcessFailure = errors.New("atecc: ecc failed to process")
+	ErrSelfTestFailed = errors.New("atecc: self-test failed")
+	ErrHealthTest     = errors.New("atecc: health test failed")
 
-	errProcessFailure = errors.New("atecc: ecc failed to process")
-	errSelfTestFailed = errors.New("atecc: self-test failed")
-	errHealthTest     = errors.New("atecc: health test failed")
-	errExecution      = errors.New("atecc: execution error")
+	// ErrExecution is used when the command was not allowed to execute.
+	//
+	// This is returned when the configuration prohibits the command, e.g.
+	// writing to a locked slot or zone, reading a secret slot or using a key
+	// which is not permitted for the operation.
+	ErrExecution = errors.New("atecc: execution error")
 
-	// errWakeSuccessful is used when device is successfully woken up.
+	// ErrWakeSuccessful is used when device is successfully woken up.
 	//
 	// This is an error for any command except for wake.
-	errWakeSuccessful = errors.New("atecc: wake successful")
+	ErrWakeSuccessful = errors.New("atecc: wake successful")
 
-	// errCRC is used for checksum missmatch or other communication error.
+	// ErrWatchdogAboutToExpire is used when there is not enough time left
+	// before the watchdog expires to execute the command.
+	ErrWatchdogAboutToExpire = errors.New("atecc: watchdog about to expire")
+
+	// ErrWatchdogTimeout is used when a command kept awake by KeepAwake would
+	// not finish before the watchdog puts the device to sleep.
+	//
+	// Unlike ErrWatchdogAboutToExpire, the command is never sent.
+	ErrWatchdogTimeout = errors.New("atecc: command would outlast watchdog")
+
+	// ErrCRC is used for checksum missmatch or other communication error.
 	//
 	// Bad CRC, command not properly received by device or other error.
 	//
 	// This is a transient error and the command should be re-transmitted.
-	errCRC = errors.New("atecc: crc or communication error")
+	ErrCRC = errors.New("atecc: crc or communication error")
 
-	errUnknown = errors.New("atecc: unknown error")
+	ErrUnknown = errors.New("atecc: unknown error")
 )
 
 // validateResponseStatusCode validates the status code returned by protocol.
@@ -47,27 +69,75 @@ func validateResponseStatusCode(response []byte) error {
 	case 0x00:
 		return nil
 	case 0x01:
-		return errCheckMacVerifyFailed
+		return ErrCheckMacVerifyFailed
 	case 0x03:
-		return errParseError
+		return ErrParseError
 	case 0x05:
-		return errProcessFailure
+		return ErrProcessFailure
 	case 0x07:
-		return errSelfTestFailed
+		return ErrSelfTestFailed
 	case 0x08:
-		return errHealthTest
+		return ErrHealthTest
 	case 0x0f:
-		return errExecution
+		return ErrExecution
 	case 0x11:
-		return errWakeSuccessful
+		return ErrWakeSuccessful
+	case 0xee:
+		return ErrWatchdogAboutToExpire
 	case 0xff:
-		return errCRC
+		return ErrCRC
 	default:
-		return errUnknown
+		return ErrUnknown
 	}
 }
 
+// StatusError is returned when the device responds with an error status.
+//
+// It carries the context of the command which failed. The underlying protocol
+// error is available through errors.Is and errors.Unwrap.
+type StatusError struct {
+	// Status is the raw status byte returned by the device.
+	Status byte
+	// Opcode is the op-code of the command that failed.
+	Opcode uint8
+	// Param1 is the first parameter of the command that failed.
+	Param1 uint8
+	// Param2 is the second parameter of the command that failed.
+	Param2 uint16
+	// SerialNumber is the serial number of the device, if known.
+	SerialNumber []byte
+	// Err is the protocol error corresponding to Status.
+	Err error
+}
+
+func newStatusError(p *packet, status byte, serialNumber []byte, err error) *StatusError {
+	return &StatusError{
+		Status:       status,
+		Opcode:       p.opcode,
+		Param1:       p.param1,
+		Param2:       p.param2,
+		SerialNumber: serialNumber,
+		Err:          err,
+	}
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf(
+		"%v (status 0x%02x, %s param1 0x%02x param2 0x%04x",
+		e.Err, e.Status, opcodeName(e.Opcode), e.Param1, e.Param2,
+	)
+	if len(e.SerialNumber) > 0 {
+		msg += ", sn " + hex.EncodeToString(e.SerialNumber)
+	}
+	return msg + ")"
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
 // Package errors.
 var (
-	errRecvBuffer = errors.New("atecc: recv buffer too small")
+	// ErrRecvBuffer is used when a response does not fit the receive buffer.
+	ErrRecvBuffer = errors.New("atecc: recv buffer too small")
 )
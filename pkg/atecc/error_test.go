@@ -0,0 +1,68 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateResponseStatusCode(t *testing.T) {
+	testCases := []struct {
+		status byte
+		want   error
+	}{
+		{0x00, nil},
+		{0x01, ErrCheckMacVerifyFailed},
+		{0x03, ErrParseError},
+		{0x05, ErrProcessFailure},
+		{0x07, ErrSelfTestFailed},
+		{0x08, ErrHealthTest},
+		{0x0f, ErrExecution},
+		{0x11, ErrWakeSuccessful},
+		{0xee, ErrWatchdogAboutToExpire},
+		{0xff, ErrCRC},
+		{0x42, ErrUnknown},
+	}
+
+	for _, tc := range testCases {
+		if err := validateResponseStatusCode([]byte{tc.status}); err != tc.want {
+			t.Errorf("0x%02x: got %v, want %v", tc.status, err, tc.want)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.lock()
+	d := newSimDev(t, sim)
+
+	// the config zone is locked, which makes the device refuse the write
+	err := d.WriteBytesZone(ctx, ZoneConfig, 0, 32, make([]byte, 32))
+	if !errors.Is(err, ErrExecution) {
+		t.Fatalf("got %v, want %v", err, ErrExecution)
+	}
+
+	var se *StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("got %T, want *StatusError", err)
+	}
+	if se.Status != 0x0f {
+		t.Errorf("status: got 0x%02x, want 0x0f", se.Status)
+	}
+	if se.Opcode != atcaWrite {
+		t.Errorf("opcode: got %s, want %s", opcodeName(se.Opcode), opcodeName(atcaWrite))
+	}
+	if se.Param1 != uint8(ZoneConfig)|atcaZoneReadWrite32 || se.Param2 != 0x0008 {
+		t.Errorf("params: got 0x%02x 0x%04x", se.Param1, se.Param2)
+	}
+	if !bytes.Equal(se.SerialNumber, simSerialNumber) {
+		t.Errorf("serial number: got %x, want %x", se.SerialNumber, simSerialNumber)
+	}
+
+	want := "atecc: execution error (status 0x0f, Write param1 0x80 param2 0x0008, sn 0123aabbccddeeffee)"
+	if se.Error() != want {
+		t.Errorf("got %q, want %q", se.Error(), want)
+	}
+}
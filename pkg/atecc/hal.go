@@ -1,5 +1,10 @@
 package atecc
 
+import (
+	"errors"
+	"io"
+)
+
 type HAL interface {
 	// Read reads up to len(p) bytes into p from the device.
 	Read(p []byte) (int, error)
@@ -10,3 +15,48 @@ type HAL interface {
 	// Wake wakes the device up.
 	Wake() error
 }
+
+// HALSleeper is implemented by a HAL which can put the device into sleep
+// state.
+//
+// A HAL may also implement io.Closer to release its transport when the device
+// is closed. Transports owned by the caller, such as an I²C bus or serial port
+// passed in through IfaceConfig, are left open.
+type HALSleeper interface {
+	// Sleep puts the device into sleep state.
+	//
+	// Unlike idle, all volatile state such as TempKey is lost.
+	Sleep() error
+}
+
+var errSleepUnsupported = errors.New("atecc: hal does not support sleep")
+
+// canSleep reports whether the HAL, after unwrapping any HAL added by this
+// package, implements HALSleeper.
+func canSleep(h HAL) bool {
+	for {
+		w, ok := h.(interface{ unwrap() HAL })
+		if !ok {
+			break
+		}
+		h = w.unwrap()
+	}
+	_, ok := h.(HALSleeper)
+	return ok
+}
+
+// halSleep puts the device into sleep state if supported by the HAL.
+func halSleep(h HAL) error {
+	if !canSleep(h) {
+		return errSleepUnsupported
+	}
+	return h.(HALSleeper).Sleep()
+}
+
+// halClose releases the transport if supported by the HAL.
+func halClose(h HAL) error {
+	if c, ok := h.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -1,29 +1,23 @@
 package atecc
 
+import "io"
+
 type halDebug struct {
 	id   string
 	l    Logger
 	next HAL
 }
 
+func (h *halDebug) unwrap() HAL {
+	return h.next
+}
+
 func (h *halDebug) Read(p []byte) (int, error) {
-	h.l.Printf("%5s >>  recv(%d)", h.id, cap(p))
-	n, err := h.next.Read(p)
-	h.l.Printf("%5s <<  recv %d(%d) %+v", h.id, n, len(p), err)
-	if n > 0 {
-		h.l.Printf("%s", hexDump(p[:n]))
-	}
-	return n, err
+	return (&rwDebug{h.id, h.l, h.next}).Read(p)
 }
 
 func (h *halDebug) Write(p []byte) (int, error) {
-	h.l.Printf("%5s >>  send", h.id)
-	if len(p) > 0 {
-		h.l.Printf("%s", hexDump(p))
-	}
-	n, err := h.next.Write(p)
-	h.l.Printf("%5s <<  send %d %+v", h.id, n, err)
-	return n, err
+	return (&rwDebug{h.id, h.l, h.next}).Write(p)
 }
 
 func (h *halDebug) Idle() error {
@@ -33,9 +27,50 @@ func (h *halDebug) Idle() error {
 	return err
 }
 
+func (h *halDebug) Sleep() error {
+	h.l.Printf("%5s >>  sleep", h.id)
+	err := halSleep(h.next)
+	h.l.Printf("%5s <<  sleep %#v", h.id, err)
+	return err
+}
+
+func (h *halDebug) Close() error {
+	h.l.Printf("%5s >>  close", h.id)
+	err := halClose(h.next)
+	h.l.Printf("%5s <<  close %#v", h.id, err)
+	return err
+}
+
 func (h *halDebug) Wake() error {
 	h.l.Printf("%5s >>  wake", h.id)
 	err := h.next.Wake()
 	h.l.Printf("%5s <<  wake %#v", h.id, err)
 	return err
 }
+
+// rwDebug logs all reads and writes to the underlying transport.
+type rwDebug struct {
+	id   string
+	l    Logger
+	next io.ReadWriter
+}
+
+func (h *rwDebug) Read(p []byte) (int, error) {
+	h.l.Printf("%5s >>  recv(%d)", h.id, cap(p))
+	n, err := h.next.Read(p)
+	h.l.Printf("%5s <<  recv %d(%d) %+v", h.id, n, len(p), err)
+	if n > 0 {
+		h.l.Printf("%s", hexDump(p[:n]))
+	}
+	return n, err
+}
+
+func (h *rwDebug) Write(p []byte) (int, error) {
+	h.l.Printf("%5s >>  send", h.id)
+	if len(p) > 0 {
+		h.l.Printf("%s", hexDump(p))
+	}
+	n, err := h.next.Write(p)
+	h.l.Printf("%5s <<  send %d %+v", h.id, n, err)
+	return n, err
+}
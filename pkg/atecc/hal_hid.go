@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 
 	"github.com/karalabe/usb"
 )
@@ -16,14 +15,16 @@ import (
 var ErrUSBNotSupported = errors.New("atecc: usb support is missing")
 
 // NewHIDDev returns an object that communicates over HID.
-func NewHIDDev(ctx context.Context, cfg IfaceConfig) (*Dev, io.Closer, error) {
+//
+// The USB device is released when the device is closed.
+func NewHIDDev(ctx context.Context, cfg IfaceConfig) (*Dev, error) {
 	if !usb.Supported() {
-		return nil, nil, ErrUSBNotSupported
+		return nil, ErrUSBNotSupported
 	}
 
 	deviceInfos, err := usb.EnumerateHid(cfg.HID.VendorID, cfg.HID.ProductID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("atecc: failed to get hid devices: %w", err)
+		return nil, fmt.Errorf("atecc: failed to get hid devices: %w", err)
 	}
 	for _, di := range deviceInfos {
 		hid, e := di.Open()
@@ -33,23 +34,55 @@ func NewHIDDev(ctx context.Context, cfg IfaceConfig) (*Dev, io.Closer, error) {
 		}
 
 		phy := newHALHID(hid, cfg)
-		hal, err := newHALKit(ctx, phy, cfg)
+		d, err := newKitDev(ctx, phy, phy, cfg)
 		if err != nil {
-			return nil, nil, err
-		}
-		var d *Dev
-		if d, err = New(ctx, hal, cfg); err != nil {
-			return nil, nil, err
+			_ = phy.Close()
+			return nil, err
 		}
-		return d, hid, err
+		return d, nil
 	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("atecc: %w", err)
+		return nil, fmt.Errorf("atecc: %w", err)
 	} else {
-		return nil, nil, errors.New("atecc: no hid devices found")
+		return nil, errors.New("atecc: no hid devices found")
+	}
+}
+
+// discoverHID returns the devices connected to all HID kits.
+func discoverHID(ctx context.Context, cfg IfaceConfig) ([]DeviceDescriptor, error) {
+	if !usb.Supported() {
+		return nil, ErrUSBNotSupported
+	}
+
+	deviceInfos, err := usb.EnumerateHid(cfg.HID.VendorID, cfg.HID.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("atecc: failed to get hid devices: %w", err)
+	}
+
+	var (
+		descs []DeviceDescriptor
+		errs  []error
+	)
+	for _, di := range deviceInfos {
+		hid, err := di.Open()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", di.Path, err))
+			continue
+		}
+
+		found, err := discoverKit(ctx, newHALHID(hid, cfg), cfg, di.Path)
+		descs = append(descs, found...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", di.Path, err))
+		}
+		_ = hid.Close()
 	}
+	return descs, errors.Join(errs...)
 }
 
+// halHID is the USB HID transport used by the kit protocol.
+//
+// The power lifecycle of the device is handled by the kit protocol on top.
 type halHID struct {
 	usb usb.Device
 	cfg IfaceConfig
@@ -73,10 +106,7 @@ func (h *halHID) Read(p []byte) (int, error) {
 	return h.usb.Read(p)
 }
 
-func (h *halHID) Idle() error {
-	return errors.New("not implemented")
-}
-
-func (h *halHID) Wake() error {
-	return errors.New("not implemented")
+// Close releases the USB device.
+func (h *halHID) Close() error {
+	return h.usb.Close()
 }
@@ -25,6 +25,7 @@ func NewI2CDev(ctx context.Context, cfg IfaceConfig) (*Dev, error) {
 var (
 	// wordWake is used to wake the i2c bus up by sending it to address 0.
 	wordWake    uint8 = 0x01
+	wordSleep   uint8 = 0x01
 	wordIdle    uint8 = 0x02
 	wordCommand uint8 = 0x03
 )
@@ -58,7 +59,7 @@ func (h *halI2C) Read(buf []byte) (int, error) {
 	}
 	size := int(buf[0])
 	if size > cap(buf) {
-		return 1, errRecvBuffer
+		return 1, ErrRecvBuffer
 	} else if size < 4 {
 		return 1, errors.New("atecc: invalid packet size")
 	}
@@ -75,6 +76,15 @@ func (h *halI2C) Idle() error {
 	return h.conn.Tx([]byte{wordIdle}, nil)
 }
 
+func (h *halI2C) Sleep() error {
+	return h.conn.Tx([]byte{wordSleep}, nil)
+}
+
+// Close does nothing, the bus is owned by the caller.
+func (h *halI2C) Close() error {
+	return nil
+}
+
 func (h *halI2C) Wake() error {
 	// TODO: move back to 100k baud if needed
 	// defer () {
@@ -83,17 +93,7 @@ func (h *halI2C) Wake() error {
 
 	var err error
 	for i := 0; i < h.cfg.RxRetries; i++ {
-		// Send wake pulse 0x01 on the 0x00 address. As described by Adafruit:
-		//
-		// > This is a hack to generate the ATECC Wake condition, which is SDA held
-		// > low for t > 60us (twlo). For an I2C clock freq of 100kHz, 8 clock
-		// > cycles will be 80us. This signal is generated by trying to address
-		// > something at 0x00. It will fail, but the pattern should wake up the
-		// > ATECC.
-		_ = h.phy.Tx([]byte{wordWake}, nil)
-
-		// Allow tWHI + tWLO to take its time.
-		time.Sleep(h.cfg.WakeDelay)
+		h.pulse()
 
 		// Return if we receive a response.
 		var r [4]byte
@@ -107,6 +107,23 @@ func (h *halI2C) Wake() error {
 	return err
 }
 
+// pulse generates the wake condition on the bus.
+//
+// Every device on the bus is woken up by the wake condition.
+func (h *halI2C) pulse() {
+	// Send wake pulse 0x01 on the 0x00 address. As described by Adafruit:
+	//
+	// > This is a hack to generate the ATECC Wake condition, which is SDA held
+	// > low for t > 60us (twlo). For an I2C clock freq of 100kHz, 8 clock
+	// > cycles will be 80us. This signal is generated by trying to address
+	// > something at 0x00. It will fail, but the pattern should wake up the
+	// > ATECC.
+	_ = h.phy.Tx([]byte{wordWake}, nil)
+
+	// Allow tWHI + tWLO to take its time.
+	time.Sleep(h.cfg.WakeDelay)
+}
+
 // checkWakeUp validates the response from the wake up call.
 func checkWakeUp(data []byte) error {
 	var (
@@ -0,0 +1,140 @@
+package atecc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrI2CDevNotSupported is returned when i2c-dev is not supported on the
+// platform.
+var ErrI2CDevNotSupported = errors.New("atecc: i2c-dev support is missing")
+
+// NewLinuxI2CDev returns an object that communicates over I²C to ATECC608A
+// using the Linux i2c-dev interface, e.g. /dev/i2c-1.
+//
+// Unlike NewI2CDev, no host initialization is required. The device file is
+// opened by this function and closed when the device is closed.
+//
+// The wake condition is generated by addressing the general call address,
+// which requires the bus to run at 100kHz or slower. The bus speed cannot be
+// changed through i2c-dev.
+func NewLinuxI2CDev(ctx context.Context, cfg IfaceConfig) (*Dev, error) {
+	f, err := openI2CDevFile(cfg.LinuxI2C.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := New(ctx, newHALI2CDev(f, cfg.LinuxI2C.Address, cfg), cfg)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// i2c-dev message flags
+const (
+	i2cMsgRead uint16 = 0x0001
+)
+
+// i2cMsg is a single I²C message, part of a combined transaction.
+type i2cMsg struct {
+	addr  uint16
+	flags uint16
+	buf   []byte
+}
+
+// i2cDevFile is an opened i2c-dev device file.
+type i2cDevFile interface {
+	// rdwr executes the messages as a single combined transaction.
+	rdwr(msgs []i2cMsg) error
+	// Close closes the device file.
+	Close() error
+}
+
+type halI2CDev struct {
+	f    i2cDevFile
+	addr uint16
+	cfg  IfaceConfig
+}
+
+func newHALI2CDev(f i2cDevFile, addr uint16, cfg IfaceConfig) *halI2CDev {
+	return &halI2CDev{
+		f:    f,
+		addr: addr,
+		cfg:  cfg,
+	}
+}
+
+func (h *halI2CDev) tx(w []byte, r []byte) error {
+	var msgs []i2cMsg
+	if len(w) > 0 {
+		msgs = append(msgs, i2cMsg{addr: h.addr, buf: w})
+	}
+	if len(r) > 0 {
+		msgs = append(msgs, i2cMsg{addr: h.addr, flags: i2cMsgRead, buf: r})
+	}
+	return h.f.rdwr(msgs)
+}
+
+func (h *halI2CDev) Write(data []byte) (int, error) {
+	cmd := append([]byte{wordCommand}, data...)
+	err := h.tx(cmd, nil)
+	return len(data), err
+}
+
+func (h *halI2CDev) Read(buf []byte) (int, error) {
+	// determine many bytes to be read
+	if err := h.tx([]byte{0x0}, buf[0:1]); err != nil {
+		return 0, err
+	}
+	size := int(buf[0])
+	if size > cap(buf) {
+		return 1, ErrRecvBuffer
+	} else if size < 4 {
+		return 1, errors.New("atecc: invalid packet size")
+	}
+
+	// read size (excluding 1 byte already read)
+	if err := h.tx(nil, buf[1:size:size]); err != nil {
+		return 1, err
+	}
+
+	return size, nil
+}
+
+func (h *halI2CDev) Idle() error {
+	return h.tx([]byte{wordIdle}, nil)
+}
+
+func (h *halI2CDev) Sleep() error {
+	return h.tx([]byte{wordSleep}, nil)
+}
+
+// Close closes the device file.
+func (h *halI2CDev) Close() error {
+	return h.f.Close()
+}
+
+func (h *halI2CDev) Wake() error {
+	var err error
+	for i := 0; i < h.cfg.RxRetries; i++ {
+		// Hold SDA low by addressing the general call address. Nothing
+		// acknowledges the address, so the error is ignored.
+		_ = h.f.rdwr([]i2cMsg{{addr: 0, buf: []byte{wordWake}}})
+
+		// Allow tWHI + tWLO to take its time.
+		time.Sleep(h.cfg.WakeDelay)
+
+		// Return if we receive a response.
+		var r [4]byte
+		if err = h.tx(nil, r[:]); err == nil {
+			if err = checkWakeUp(r[:]); err == nil {
+				return nil
+			}
+		}
+	}
+
+	return err
+}
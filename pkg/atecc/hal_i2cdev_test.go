@@ -0,0 +1,113 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// errI2CNack is returned when no target acknowledges the address.
+var errI2CNack = errors.New("i2c: nack")
+
+// fakeI2CDevFile is an i2c-dev device file with a simulated device on the
+// bus.
+type fakeI2CDevFile struct {
+	sim  *simDevice
+	addr uint16
+
+	resp   []byte // response being read
+	txs    int    // number of transactions
+	closed bool
+}
+
+func (f *fakeI2CDevFile) rdwr(msgs []i2cMsg) error {
+	f.txs++
+	for _, msg := range msgs {
+		if msg.addr != f.addr {
+			// the general call address holds SDA low long enough
+			if msg.addr == 0 && !f.sim.awake {
+				_ = f.sim.Wake()
+				f.resp = []byte{0x04, 0x11, 0x33, 0x43}
+			}
+			return errI2CNack
+		}
+		if !f.sim.awake {
+			return errI2CNack
+		}
+
+		if msg.flags&i2cMsgRead != 0 {
+			if len(f.resp) == 0 {
+				var buf [256]byte
+				n, err := f.sim.Read(buf[:])
+				if err != nil {
+					return errI2CNack
+				}
+				f.resp = buf[:n]
+			}
+			n := copy(msg.buf, f.resp)
+			f.resp = f.resp[n:]
+			continue
+		}
+
+		switch msg.buf[0] {
+		case 0x00: // reset
+		case wordCommand:
+			_, _ = f.sim.Write(msg.buf[1:])
+		case wordIdle:
+			_ = f.sim.Idle()
+		case wordSleep:
+			_ = f.sim.Sleep()
+		default:
+			return errors.New("i2c: unknown word address")
+		}
+	}
+	return nil
+}
+
+func (f *fakeI2CDevFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestLinuxI2CDev(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	f := &fakeI2CDevFile{sim: sim, addr: 0x60}
+
+	cfg := ConfigATECCX08A_LinuxI2CDefault("/dev/i2c-1")
+	cfg.WakeDelay = time.Microsecond
+	d, err := New(ctx, newHALI2CDev(f, cfg.LinuxI2C.Address, cfg), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sn, err := d.SerialNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(sn, simSerialNumber) {
+		t.Errorf("got %x, want %x", sn, simSerialNumber)
+	}
+	if sim.awake {
+		t.Error("device not idle after command")
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !f.closed {
+		t.Error("device file not closed")
+	}
+}
+
+func TestLinuxI2CDevWrongAddress(t *testing.T) {
+	f := &fakeI2CDevFile{sim: newSimDevice(), addr: 0x60}
+	h := newHALI2CDev(f, 0x35, IfaceConfig{RxRetries: 2})
+	if err := h.Wake(); !errors.Is(err, errI2CNack) {
+		t.Errorf("got %v, want %v", err, errI2CNack)
+	}
+	if f.txs != 4 {
+		t.Errorf("got %d transactions, want 4", f.txs)
+	}
+}
@@ -1,33 +1,103 @@
 package atecc
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 )
 
+// NewKitDev returns an object that communicates using the kit protocol.
+//
+// The kit protocol is line framed ASCII, which is used over rw. This allows
+// the protocol to be used over any byte-stream transport, such as a USB CDC
+// serial port.
+//
+// The caller owns rw, it is not closed when the device is closed.
+func NewKitDev(ctx context.Context, rw io.ReadWriter, cfg IfaceConfig) (*Dev, error) {
+	return newKitDev(ctx, rw, nil, cfg)
+}
+
+// newKitDev returns a device using the kit protocol over phy.
+//
+// The closer is closed once the device is closed, unless nil.
+func newKitDev(ctx context.Context, phy io.ReadWriter, closer io.Closer, cfg IfaceConfig) (*Dev, error) {
+	hal, err := newHALKit(ctx, phy, closer, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return New(ctx, hal, cfg)
+}
+
+// NewKitSerialDev returns an object that communicates using the kit protocol
+// over a serial port.
+//
+// The serial port is opened using the path and baud rate configured. It is
+// closed when the device is closed.
+func NewKitSerialDev(ctx context.Context, cfg IfaceConfig) (*Dev, error) {
+	port, err := OpenSerial(cfg.KitSerial.Path, cfg.KitSerial.BaudRate, 8)
+	if err != nil {
+		return nil, fmt.Errorf("atecc: failed to open serial port: %w", err)
+	}
+
+	d, err := newKitDev(ctx, port, port, cfg)
+	if err != nil {
+		_ = port.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
 type halKit struct {
-	phy HAL
-	buf []byte
-	cfg IfaceConfig
+	phy    io.ReadWriter
+	closer io.Closer
+	buf    []byte
+	cfg    IfaceConfig
+
+	// r reads lines from phy, unless the transport is packet based.
+	r *bufio.Reader
 }
 
 var errNoDevice = errors.New("atecc: no device found")
 
-func newHALKit(ctx context.Context, phy HAL, cfg IfaceConfig) (*halKit, error) {
-	buf := make([]byte, getPacketSize(cfg))
-	phy = &halDebug{"kit", getLogger(cfg), phy}
-	kit := &halKit{phy, buf, cfg}
+func newHALKit(ctx context.Context, phy io.ReadWriter, closer io.Closer, cfg IfaceConfig) (*halKit, error) {
+	kit := newHALKitUnselected(phy, closer, cfg)
 	return kit, kit.init(ctx)
 }
 
+// newHALKitUnselected returns a kit where no device has been selected yet.
+func newHALKitUnselected(phy io.ReadWriter, closer io.Closer, cfg IfaceConfig) *halKit {
+	phy = &rwDebug{"kit", getLogger(cfg), phy}
+	kit := &halKit{phy: phy, closer: closer, cfg: cfg}
+	if size := getPacketSize(cfg); size > 0 {
+		kit.buf = make([]byte, size)
+	} else {
+		kit.buf = make([]byte, hex.EncodedLen(kitMsgSize)+kitRxWrapSize)
+		kit.r = bufio.NewReader(phy)
+	}
+	return kit
+}
+
 func kitIdFromDeviceType(deviceType DeviceType) string {
 	switch deviceType {
 	case DeviceATECC608:
 		return "ECC608"
+	case DeviceATECC508:
+		return "ECC508A"
+	case DeviceATSHA204:
+		return "SHA204A"
+	case DeviceECC204:
+		return "ECC204"
+	case DeviceSHA104:
+		return "SHA104"
+	case DeviceSHA105:
+		return "SHA105"
+	case DeviceTA010:
+		return "TA010"
 	default:
 		return "unknown"
 	}
@@ -36,6 +106,18 @@ func kitIdFromDeviceType(deviceType DeviceType) string {
 func deviceTypeFromKitId(id string) (DeviceType, error) {
 	if strings.HasPrefix(id, "ECC6") {
 		return DeviceATECC608, nil
+	} else if strings.HasPrefix(id, "ECC5") {
+		return DeviceATECC508, nil
+	} else if strings.HasPrefix(id, "SHA204") {
+		return DeviceATSHA204, nil
+	} else if strings.HasPrefix(id, "ECC204") {
+		return DeviceECC204, nil
+	} else if strings.HasPrefix(id, "SHA104") {
+		return DeviceSHA104, nil
+	} else if strings.HasPrefix(id, "SHA105") {
+		return DeviceSHA105, nil
+	} else if strings.HasPrefix(id, "TA010") {
+		return DeviceTA010, nil
 	} else {
 		return DeviceType(0), errors.New("atecc: unknown device type")
 	}
@@ -87,43 +169,71 @@ func (h *halKit) init(ctx context.Context) error {
 		devIndex = h.cfg.HID.DevIndex
 		kitType = h.cfg.HID.KitType
 		devIdentity = h.cfg.HID.DevIdentity
+	case IfaceKitSerial:
+		devIndex = h.cfg.KitSerial.DevIndex
+		kitType = h.cfg.KitSerial.KitType
+		devIdentity = h.cfg.KitSerial.DevIdentity
 	default:
 		kitType = KitTypeAuto
 	}
 
-	// Iterate to find the target device
-	for i := 0; i < kitMaxScanCount; i++ {
-		dev, err := h.getKitDeviceByIndex(i)
-		if errors.Is(err, errNoDevice) {
-			continue
-		} else if err != nil {
-			return err
-		}
+	devs, err := h.devices()
+	if err != nil {
+		return err
+	}
 
+	// Iterate to find the target device. Unless the device type is strict, a
+	// device of another type is picked if none of the configured type is
+	// found, and detection is left to verify the type once opened.
+	var found *kitDevice
+	for i, dev := range devs {
 		// Check if the returned device is a device we want to pick
-		if devIndex != 0 && devIndex != i {
+		if devIndex != 0 && devIndex != dev.Index {
 			continue
 		}
 		if devIdentity != 0 && devIdentity != dev.Address {
 			continue
 		}
-		if h.cfg.DeviceType != dev.DeviceType {
-			continue
-		}
 		if kitType != KitTypeAuto && kitType != dev.KitType {
 			continue
 		}
-
-		if kitType != KitTypeAuto {
-			if err := h.selectInterface(kitType); err != nil {
-				return err
-			}
+		if h.cfg.DeviceType == dev.DeviceType {
+			found = &devs[i]
+			break
+		}
+		if found == nil && !h.cfg.StrictDeviceType {
+			found = &devs[i]
 		}
+	}
+	if found == nil {
+		return errors.New("atecc: failed to discover device")
+	}
 
-		return h.selectDevice(dev.Address)
+	if kitType != KitTypeAuto {
+		if err := h.selectInterface(kitType); err != nil {
+			return err
+		}
 	}
 
-	return errors.New("atecc: failed to discover device")
+	// The kit id of every command depends on the device type.
+	h.cfg.DeviceType = found.DeviceType
+	return h.selectDevice(found.Address)
+}
+
+// devices returns all devices connected to the kit.
+func (h *halKit) devices() ([]kitDevice, error) {
+	var devs []kitDevice
+	for i := 0; i < kitMaxScanCount; i++ {
+		dev, err := h.getKitDeviceByIndex(i)
+		if errors.Is(err, errNoDevice) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		dev.Index = i
+		devs = append(devs, dev)
+	}
+	return devs, nil
 }
 
 func (h *halKit) Wake() error {
@@ -145,6 +255,20 @@ func (h *halKit) Idle() error {
 	return h.execute([]byte(command))
 }
 
+func (h *halKit) Sleep() error {
+	kitId := kitIdFromDeviceType(h.cfg.DeviceType)
+	command := fmt.Sprintf("%c:s()\n", kitId[0])
+	return h.execute([]byte(command))
+}
+
+// Close closes the underlying transport, unless owned by the caller.
+func (h *halKit) Close() error {
+	if h.closer == nil {
+		return nil
+	}
+	return h.closer.Close()
+}
+
 func (h *halKit) Write(data []byte) (int, error) {
 	kitId := kitIdFromDeviceType(h.cfg.DeviceType)
 	payload := strings.ToUpper(hex.EncodeToString(data))
@@ -154,8 +278,11 @@ func (h *halKit) Write(data []byte) (int, error) {
 
 func (h *halKit) Read(dst []byte) (int, error) {
 	msg := hex.EncodedLen(len(dst)) + kitRxWrapSize
-	pkt := h.cfg.HID.PacketSize
-	buf := make([]byte, (msg/pkt+1)*pkt)
+	if h.r == nil {
+		pkt := len(h.buf)
+		msg = (msg/pkt + 1) * pkt
+	}
+	buf := make([]byte, msg)
 
 	n, err := h.phyRecv(buf)
 	if err != nil {
@@ -216,6 +343,7 @@ type kitDevice struct {
 	DeviceType DeviceType
 	KitType    KitType
 	Address    uint8
+	Index      int
 }
 
 func parseKitDevice(buf []byte) (kitDevice, error) {
@@ -240,11 +368,16 @@ func parseKitDevice(buf []byte) (kitDevice, error) {
 	} else if kt, err := kitTypeFromKitIface(kitIface); err != nil {
 		return kitDevice{}, err
 	} else {
-		return kitDevice{dt, kt, address}, nil
+		return kitDevice{dt, kt, address, int(index)}, nil
 	}
 }
 
 func (h *halKit) phySend(txData []byte) (int, error) {
+	if h.r != nil {
+		return h.phy.Write(txData)
+	}
+
+	// packet based transports send zero padded packets
 	left := len(txData)
 	sent := 0
 	for left > 0 {
@@ -266,6 +399,10 @@ func (h *halKit) phySend(txData []byte) (int, error) {
 }
 
 func (h *halKit) phyRecv(data []byte) (int, error) {
+	if h.r != nil {
+		return h.phyRecvLine(data)
+	}
+
 	left := len(data)
 	read := 0
 	for left > 0 {
@@ -294,7 +431,29 @@ func (h *halKit) phyRecv(data []byte) (int, error) {
 	return read, nil
 }
 
+// phyRecvLine receives a single line from a byte-stream transport.
+//
+// The line ending is not included in data.
+func (h *halKit) phyRecvLine(data []byte) (int, error) {
+	line, err := h.r.ReadBytes('\n')
+	if err != nil {
+		return 0, err
+	}
+	line = bytes.TrimRight(line, "\r\n")
+
+	// error out to make sure we never loose any data
+	if len(line) > cap(data) {
+		return len(line), errors.New("atecc: buffer overflow")
+	}
+
+	return copy(data[:cap(data)], line), nil
+}
+
 func kitParseRsp(reply []byte, dst []byte) (int, error) {
+	if len(reply) < 3 {
+		return 0, errors.New("atecc: invalid kit response")
+	}
+
 	var status [1]byte
 	n, err := hex.Decode(status[:], reply[0:2])
 	if err != nil {
@@ -309,18 +468,21 @@ func kitParseRsp(reply []byte, dst []byte) (int, error) {
 	}
 	size := hex.DecodedLen(index)
 	if size > cap(dst) {
-		return 0, errRecvBuffer
+		return 0, ErrRecvBuffer
 	}
 
 	body := reply[3 : 3+index]
 	return hex.Decode(dst, body)
 }
 
+// getPacketSize returns the packet size of the transport.
+//
+// Byte-stream transports have no packet size and zero is returned.
 func getPacketSize(cfg IfaceConfig) int {
 	switch cfg.IfaceType {
 	case IfaceHID:
 		return cfg.HID.PacketSize
 	default:
-		panic("atecc: unsupported iface type")
+		return 0
 	}
 }
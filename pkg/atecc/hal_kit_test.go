@@ -1,21 +1,183 @@
 package atecc
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
 
-func TestParseKitDevice(t *testing.T) {
-	buf := []byte("ECC608B TWI 00(6C)")
+// kitBoard emulates a dev kit with a simulated device attached.
+//
+// If packetSize is set, the kit communicates using zero padded packets like
+// HID. Otherwise the kit communicates over a byte-stream.
+type kitBoard struct {
+	sim        *simDevice
+	packetSize int
+	// device is the device reported by the kit, an ECC608A if empty.
+	device string
 
-	dev, err := parseKitDevice(buf)
-	if err != nil {
-		t.Fatal(err)
+	in  []byte // received, but not yet processed data
+	out []byte // data to be transmitted
+}
+
+func (k *kitBoard) Write(p []byte) (int, error) {
+	if k.packetSize > 0 {
+		if len(p) != k.packetSize {
+			return 0, fmt.Errorf("kit: invalid packet size %d", len(p))
+		}
+		k.in = append(k.in, bytes.TrimRight(p, "\x00")...)
+	} else {
+		k.in = append(k.in, p...)
+	}
+
+	for {
+		index := bytes.IndexByte(k.in, '\n')
+		if index == -1 {
+			break
+		}
+		line := string(k.in[:index])
+		k.in = k.in[index+1:]
+		k.out = append(k.out, k.command(line)+"\n"...)
+	}
+	return len(p), nil
+}
+
+func (k *kitBoard) Read(p []byte) (int, error) {
+	if len(k.out) == 0 {
+		return 0, errors.New("kit: read timeout")
+	}
+	if k.packetSize > 0 {
+		p = p[:k.packetSize]
+		for i := range p {
+			p[i] = 0
+		}
+	}
+	n := copy(p, k.out)
+	k.out = k.out[n:]
+	if k.packetSize > 0 {
+		return k.packetSize, nil
+	}
+	return n, nil
+}
+
+// command executes the kit command and returns the reply.
+func (k *kitBoard) command(line string) string {
+	var (
+		name string
+		arg  string
+	)
+	if i := strings.IndexByte(line, '('); i != -1 && strings.HasSuffix(line, ")") {
+		name, arg = line[:i], line[i+1:len(line)-1]
+	}
+
+	switch name {
+	case "board:device":
+		if arg == "00" && k.device != "" {
+			return k.device + " TWI 00(C0)"
+		} else if arg == "00" {
+			return "ECC608A TWI 00(C0)"
+		}
+		return "no_device"
+	case "E:physical:select":
+		return "00()"
+	case "E:w":
+		_ = k.sim.Wake()
+		return "00(04113343)"
+	case "E:i":
+		_ = k.sim.Idle()
+		return "00()"
+	case "E:s":
+		_ = k.sim.Sleep()
+		return "00()"
+	case "E:t":
+		cmd, err := hex.DecodeString(arg)
+		if err != nil {
+			return "03()"
+		}
+		var buf [256]byte
+		if _, err := k.sim.Write(cmd); err != nil {
+			return "0F()"
+		}
+		n, err := k.sim.Read(buf[:])
+		if err != nil {
+			return "0F()"
+		}
+		return "00(" + strings.ToUpper(hex.EncodeToString(buf[:n])) + ")"
+	default:
+		return "03()"
 	}
-	if dev.DeviceType != DeviceATECC608 {
-		t.Errorf("%v != %v", dev.DeviceType, DeviceATECC608)
+}
+
+func TestKitDev(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  IfaceConfig
+	}{
+		{"hid", ConfigATECCX08A_KitHIDDefault()},
+		{"serial", ConfigATECCX08A_KitSerialDefault("/dev/null")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			sim := newSimDevice()
+			kit := &kitBoard{sim: sim, packetSize: getPacketSize(tc.cfg)}
+
+			d, err := NewKitDev(ctx, kit, tc.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sn, err := d.SerialNumber(ctx)
+			if err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(sn, simSerialNumber) {
+				t.Errorf("got %x, want %x", sn, simSerialNumber)
+			}
+
+			// read a full block, which spans multiple packets for HID
+			conf, err := d.ReadConfigZone(ctx)
+			if err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(conf, sim.config[:]) {
+				t.Errorf("got %x, want %x", conf, sim.config)
+			}
+
+			if sim.awake {
+				t.Error("device not idle after command")
+			}
+			if len(kit.in) != 0 || len(kit.out) != 0 {
+				t.Errorf("unexpected data left: %q %q", kit.in, kit.out)
+			}
+		})
 	}
-	if dev.KitType != KitTypeI2C {
-		t.Errorf("%v != %v", dev.KitType, KitTypeI2C)
+}
+
+func TestKitDevDetect(t *testing.T) {
+	ctx := context.Background()
+	cfg := ConfigATECCX08A_KitSerialDefault("/dev/null")
+
+	sim := newSimDevice()
+	sim.revision = [4]byte{0x00, 0x00, 0x50, 0x00}
+	copy(sim.config[4:], sim.revision[:])
+	kit := &kitBoard{sim: sim, device: "ECC508A"}
+
+	// the configured type is only required when strict
+	cfg.StrictDeviceType = true
+	if _, err := NewKitDev(ctx, kit, cfg); err == nil {
+		t.Error("expected error for a strict device type")
+	}
+
+	cfg.StrictDeviceType = false
+	d, err := NewKitDev(ctx, kit, cfg)
+	if err != nil {
+		t.Fatal(err)
 	}
-	if dev.Address != 0x6c {
-		t.Errorf("x%0x != x%0x", dev.Address, 0x6c)
+	if got := d.DeviceType(); got != DeviceATECC508 {
+		t.Errorf("got %s, want %s", got, DeviceATECC508)
 	}
 }
@@ -0,0 +1,38 @@
+package atecc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+)
+
+// NewNetDev returns an object that communicates with a remote device using
+// the kit protocol over TCP.
+//
+// The remote end is expected to be served by a KitServer. If TLS is
+// configured, the connection is encrypted. The connection is closed when the
+// device is closed.
+func NewNetDev(ctx context.Context, cfg IfaceConfig) (*Dev, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+	if cfg.Net.TLS != nil {
+		d := &tls.Dialer{Config: cfg.Net.TLS}
+		conn, err = d.DialContext(ctx, "tcp", cfg.Net.Address)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", cfg.Net.Address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("atecc: failed to connect: %w", err)
+	}
+
+	d, err := newKitDev(ctx, conn, conn, cfg)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,213 @@
+package atecc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// NewSWIDev returns an object that communicates over SWI to ATECC608A.
+//
+// The single-wire interface is driven through an UART where both TX and RX
+// are connected to the SDA pin of the device. The serial port is expected to
+// be configured for 7 data bits, no parity and one stop bit.
+func NewSWIDev(ctx context.Context, cfg IfaceConfig) (*Dev, error) {
+	if cfg.SWI.Port == nil {
+		return nil, errors.New("atecc: missing swi serial port")
+	}
+	return New(ctx, newHALSWI(cfg.SWI.Port, cfg), cfg)
+}
+
+// SWI baud rates.
+const (
+	// swiBaudRate is the rate used to transmit tokens.
+	//
+	// One UART frame of 7 data bits is used for each token.
+	swiBaudRate = 230400
+
+	// swiWakeBaudRate is the rate used to transmit the wake token.
+	//
+	// A zero byte at this rate holds the line low for longer than tWLO.
+	swiWakeBaudRate = 115200
+)
+
+// swi flags are sent by the host to indicate what follows.
+const (
+	swiFlagCommand  uint8 = 0x77
+	swiFlagTransmit uint8 = 0x88
+	swiFlagIdle     uint8 = 0xbb
+	swiFlagSleep    uint8 = 0xcc
+)
+
+// swi tokens represent a single bit on the wire.
+const (
+	swiToken0 uint8 = 0x7d
+	swiToken1 uint8 = 0x7f
+)
+
+type halSWI struct {
+	port SerialPort
+	cfg  IfaceConfig
+}
+
+func newHALSWI(port SerialPort, cfg IfaceConfig) *halSWI {
+	return &halSWI{
+		port: port,
+		cfg:  cfg,
+	}
+}
+
+func (h *halSWI) Write(data []byte) (int, error) {
+	if err := h.sendFlag(swiFlagCommand); err != nil {
+		return 0, err
+	}
+	if err := h.send(data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (h *halSWI) Read(buf []byte) (int, error) {
+	if err := h.sendFlag(swiFlagTransmit); err != nil {
+		return 0, err
+	}
+
+	// determine many bytes to be read
+	if err := h.recv(buf[0:1]); err != nil {
+		return 0, err
+	}
+	size := int(buf[0])
+	if size > cap(buf) {
+		return 1, ErrRecvBuffer
+	} else if size < 4 {
+		return 1, errors.New("atecc: invalid packet size")
+	}
+
+	// read size (excluding 1 byte already read)
+	if err := h.recv(buf[1:size:size]); err != nil {
+		return 1, err
+	}
+
+	return size, nil
+}
+
+func (h *halSWI) Idle() error {
+	return h.sendFlag(swiFlagIdle)
+}
+
+func (h *halSWI) Sleep() error {
+	return h.sendFlag(swiFlagSleep)
+}
+
+// Close does nothing, the serial port is owned by the caller.
+func (h *halSWI) Close() error {
+	return nil
+}
+
+func (h *halSWI) Wake() error {
+	var err error
+	for i := 0; i < h.cfg.RxRetries; i++ {
+		if err = h.wake(); err != nil {
+			continue
+		}
+
+		// Allow tWHI to take its time.
+		time.Sleep(h.cfg.WakeDelay)
+
+		// Return if we receive a response.
+		var r [4]byte
+		if err = h.sendFlag(swiFlagTransmit); err != nil {
+			continue
+		}
+		if err = h.recv(r[:]); err == nil {
+			if err = checkWakeUp(r[:]); err == nil {
+				return nil
+			}
+		}
+	}
+
+	return err
+}
+
+// wake sends the wake token.
+//
+// The wake token is a zero byte sent at a lower baud rate, which holds the
+// line low for long enough to wake the device up.
+func (h *halSWI) wake() error {
+	if err := h.port.SetBaudRate(swiWakeBaudRate); err != nil {
+		return err
+	}
+	_, werr := h.port.Write([]byte{0x00})
+	if werr == nil && h.cfg.SWI.Echo {
+		var echo [1]byte
+		_, werr = io.ReadFull(h.port, echo[:])
+	}
+	if err := h.port.SetBaudRate(swiBaudRate); err != nil {
+		return err
+	}
+	return werr
+}
+
+func (h *halSWI) sendFlag(flag uint8) error {
+	return h.send([]byte{flag})
+}
+
+// send encodes data as tokens and writes them to the port.
+//
+// If the port echoes the transmitted tokens, they are read back and discarded.
+func (h *halSWI) send(data []byte) error {
+	tokens := swiEncode(data)
+	if _, err := h.port.Write(tokens); err != nil {
+		return err
+	}
+	if h.cfg.SWI.Echo {
+		if _, err := io.ReadFull(h.port, tokens); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recv reads tokens from the port and decodes them into data.
+func (h *halSWI) recv(data []byte) error {
+	tokens := make([]byte, len(data)*8)
+	if _, err := io.ReadFull(h.port, tokens); err != nil {
+		return err
+	}
+	swiDecode(data, tokens)
+	return nil
+}
+
+// swiEncode encodes each bit of data into a token, least significant bit
+// first.
+func swiEncode(data []byte) []byte {
+	tokens := make([]byte, 0, len(data)*8)
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			if b&(1<<i) != 0 {
+				tokens = append(tokens, swiToken1)
+			} else {
+				tokens = append(tokens, swiToken0)
+			}
+		}
+	}
+	return tokens
+}
+
+// swiDecode decodes tokens into data, least significant bit first.
+//
+// Tokens are matched loosely to tolerate timing differences between the host
+// and the device, where a one might be received as 0x7e and a zero as
+// anything else.
+func swiDecode(data []byte, tokens []byte) {
+	for i := range data {
+		var b byte
+		for j, tok := range tokens[i*8 : i*8+8] {
+			if tok^swiToken1 < 2 {
+				b |= 1 << j
+			}
+		}
+		data[i] = b
+	}
+}
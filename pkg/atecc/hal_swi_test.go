@@ -0,0 +1,163 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// swiLoopback is a serial port wired to a simulated device over SWI.
+//
+// Transmitted tokens are echoed back, like an UART with both TX and RX
+// connected to the SWI pin.
+type swiLoopback struct {
+	sim  *simDevice
+	baud int
+
+	rx     []byte // tokens to be received
+	tokens []byte // tokens of the byte currently being transmitted
+	flag   uint8  // last flag received
+	cmd    []byte // command packet being transmitted
+	woken  bool   // wake token received, response pending
+}
+
+func (l *swiLoopback) SetBaudRate(baud int) error {
+	l.baud = baud
+	return nil
+}
+
+func (l *swiLoopback) Write(p []byte) (int, error) {
+	l.rx = append(l.rx, p...)
+	if l.baud == swiWakeBaudRate {
+		if bytes.Equal(p, []byte{0x00}) {
+			l.woken = l.sim.Wake() == nil
+		}
+		return len(p), nil
+	} else if l.baud != swiBaudRate {
+		return len(p), nil
+	}
+
+	for _, tok := range p {
+		l.tokens = append(l.tokens, tok)
+		if len(l.tokens) == 8 {
+			var b [1]byte
+			swiDecode(b[:], l.tokens)
+			l.tokens = l.tokens[:0]
+			l.receive(b[0])
+		}
+	}
+	return len(p), nil
+}
+
+// receive handles a single byte received by the device.
+func (l *swiLoopback) receive(b byte) {
+	if l.flag == swiFlagCommand {
+		l.cmd = append(l.cmd, b)
+		if len(l.cmd) == int(l.cmd[0]) {
+			_, _ = l.sim.Write(l.cmd)
+			l.cmd = nil
+			l.flag = 0
+		}
+		return
+	}
+
+	l.flag = b
+	switch b {
+	case swiFlagTransmit:
+		var buf [256]byte
+		if l.woken {
+			l.woken = false
+			l.rx = append(l.rx, swiEncode([]byte{0x04, 0x11, 0x33, 0x43})...)
+		} else if n, err := l.sim.Read(buf[:]); err == nil {
+			l.rx = append(l.rx, swiEncode(buf[:n])...)
+		}
+	case swiFlagIdle:
+		_ = l.sim.Idle()
+	case swiFlagSleep:
+		_ = l.sim.Sleep()
+	}
+}
+
+func (l *swiLoopback) Read(p []byte) (int, error) {
+	if len(l.rx) == 0 {
+		return 0, errors.New("swi: read timeout")
+	}
+	n := copy(p, l.rx)
+	l.rx = l.rx[n:]
+	return n, nil
+}
+
+func TestSWIEncoding(t *testing.T) {
+	tokens := swiEncode([]byte{swiFlagCommand})
+	want := []byte{0x7f, 0x7f, 0x7f, 0x7d, 0x7f, 0x7f, 0x7f, 0x7d}
+	if !bytes.Equal(tokens, want) {
+		t.Errorf("got %x, want %x", tokens, want)
+	}
+
+	// a one might be received slightly off
+	tokens[0] = 0x7e
+	var b [1]byte
+	swiDecode(b[:], tokens)
+	if b[0] != swiFlagCommand {
+		t.Errorf("got 0x%02x, want 0x%02x", b[0], swiFlagCommand)
+	}
+}
+
+func TestSWIDev(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	port := &swiLoopback{sim: sim, baud: swiBaudRate}
+
+	cfg := ConfigATECCX08A_SWIDefault(port)
+	cfg.WakeDelay = time.Microsecond
+	cfg.RxRetries = 1
+	d, err := NewSWIDev(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port.baud != swiBaudRate {
+		t.Errorf("baud: got %d, want %d", port.baud, swiBaudRate)
+	}
+
+	sn, err := d.SerialNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(sn, simSerialNumber) {
+		t.Errorf("got %x, want %x", sn, simSerialNumber)
+	}
+
+	if sim.awake {
+		t.Error("device not idle after command")
+	}
+	if err := d.Sleep(); err != nil {
+		t.Fatal(err)
+	}
+	if len(port.rx) != 0 {
+		t.Errorf("unexpected tokens left: %x", port.rx)
+	}
+
+	// error responses are decoded and returned
+	err = d.WriteBytesZone(ctx, ZoneConfig, 0, 0, make([]byte, 32))
+	if !errors.Is(err, ErrExecution) {
+		t.Errorf("got %v, want %v", err, ErrExecution)
+	}
+}
+
+func TestSWIDevNoResponse(t *testing.T) {
+	port := &swiLoopback{sim: newSimDevice(), baud: swiBaudRate}
+	h := newHALSWI(port, IfaceConfig{RxRetries: 1, SWI: SWIConfig{Port: port, Echo: true}})
+
+	// no command has been sent, so there is nothing to transmit
+	var buf [4]byte
+	if _, err := h.Read(buf[:]); err == nil {
+		t.Error("expected error")
+	}
+
+	// without discarding the echo, it is misinterpreted as the response
+	h.cfg.SWI.Echo = false
+	if err := h.Wake(); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,84 @@
+package atecc
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// i2c-dev ioctl requests
+const (
+	ioctlI2CRdwr = 0x0707
+)
+
+// linuxI2CMsg matches struct i2c_msg in linux/i2c.h.
+type linuxI2CMsg struct {
+	addr  uint16
+	flags uint16
+	len   uint16
+	buf   *byte
+}
+
+// linuxI2CRdwrData matches struct i2c_rdwr_ioctl_data in linux/i2c-dev.h.
+type linuxI2CRdwrData struct {
+	msgs  *linuxI2CMsg
+	nmsgs uint32
+}
+
+type linuxI2CDevFile struct {
+	f *os.File
+}
+
+func openI2CDevFile(path string) (i2cDevFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &linuxI2CDevFile{f}, nil
+}
+
+func (f *linuxI2CDevFile) rdwr(msgs []i2cMsg) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	lmsgs := make([]linuxI2CMsg, len(msgs))
+	for i, msg := range msgs {
+		lmsgs[i] = linuxI2CMsg{
+			addr:  msg.addr,
+			flags: msg.flags,
+			len:   uint16(len(msg.buf)),
+		}
+		if len(msg.buf) > 0 {
+			lmsgs[i].buf = &msg.buf[0]
+		}
+	}
+	data := linuxI2CRdwrData{
+		msgs:  &lmsgs[0],
+		nmsgs: uint32(len(lmsgs)),
+	}
+
+	rc, err := f.f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = rc.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(
+			syscall.SYS_IOCTL, fd, ioctlI2CRdwr, uintptr(unsafe.Pointer(&data)),
+		)
+	})
+	runtime.KeepAlive(msgs)
+	runtime.KeepAlive(lmsgs)
+	if err != nil {
+		return err
+	} else if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func (f *linuxI2CDevFile) Close() error {
+	return f.f.Close()
+}
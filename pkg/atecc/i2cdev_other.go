@@ -0,0 +1,7 @@
+//go:build !linux
+
+package atecc
+
+func openI2CDevFile(path string) (i2cDevFile, error) {
+	return nil, ErrI2CDevNotSupported
+}
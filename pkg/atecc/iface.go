@@ -1,6 +1,7 @@
 package atecc
 
 import (
+	"crypto/tls"
 	"time"
 
 	"periph.io/x/conn/v3/i2c"
@@ -11,8 +12,31 @@ type IfaceType int
 const (
 	IfaceI2C IfaceType = iota
 	IfaceHID
+	IfaceSWI
+	IfaceKitSerial
+	IfaceNet
+	IfaceLinuxI2C
 )
 
+func (it IfaceType) String() string {
+	switch it {
+	case IfaceI2C:
+		return "i2c"
+	case IfaceHID:
+		return "hid"
+	case IfaceSWI:
+		return "swi"
+	case IfaceKitSerial:
+		return "serial"
+	case IfaceNet:
+		return "net"
+	case IfaceLinuxI2C:
+		return "i2c-dev"
+	default:
+		return "unknown"
+	}
+}
+
 // IfaceConfig is the configuration object for a device.
 //
 // Logical device configurations describe the device type and logical
@@ -21,26 +45,90 @@ type IfaceConfig struct {
 	// IfaceType affects how communication with the device is done.
 	IfaceType IfaceType
 	// DeviceType affects how communication with the device is done.
+	//
+	// The device type is detected when the device is opened. If it differs,
+	// the detected type is used and a warning is written to Debug.
 	DeviceType DeviceType
+	// StrictDeviceType makes opening the device fail with
+	// ErrDeviceTypeMismatch if the detected type differs from DeviceType.
+	//
+	// Kits only discover devices of DeviceType when strict. Otherwise a
+	// device of another type is used if there is none of DeviceType.
+	StrictDeviceType bool
 	// I2C contains I²C specific configuration.
 	I2C I2CConfig
+	// LinuxI2C contains configuration for I²C using Linux i2c-dev.
+	LinuxI2C LinuxI2CConfig
 	// HID contains HID specific configuration.
 	HID HIDConfig
+	// SWI contains SWI specific configuration.
+	SWI SWIConfig
+	// KitSerial contains configuration for the kit protocol over serial.
+	KitSerial KitSerialConfig
+	// Net contains configuration for the kit protocol over TCP.
+	Net NetConfig
 	// WakeDelay defines the time to wait for the device before waking up.
 	//
 	// This represents the tWHI + tWLO and is configured based on device type.
 	WakeDelay time.Duration
 	// RxRetries is the number of retries to attempt when receiving data.
 	RxRetries int
+	// Poll configures polling for responses, rather than waiting for the
+	// maximum execution time of each command.
+	Poll PollConfig
 	// Debug is used for debug output.
 	Debug Logger
 }
 
+// PollConfig configures how the response of a command is waited for.
+//
+// The execution times given in the datasheet are the maximum times, most
+// commands finish well before that. When polling, the response is read
+// repeatedly until the device responds. This requires a HAL where reading
+// fails while the device is busy, like I²C where the device does not
+// acknowledge its address until the command has finished.
+type PollConfig struct {
+	// InitialDelay is the time to wait before the first read.
+	InitialDelay time.Duration
+	// Interval is the time between reads. Polling is disabled if zero.
+	Interval time.Duration
+	// MaxTime is the time after which polling gives up. If zero, the maximum
+	// execution time of the command is used.
+	MaxTime time.Duration
+}
+
+// DefaultPollConfig is the polling configuration used for I²C by default.
+var DefaultPollConfig = PollConfig{
+	InitialDelay: 1 * time.Millisecond,
+	Interval:     2 * time.Millisecond,
+	MaxTime:      2500 * time.Millisecond,
+}
+
 type I2CConfig struct {
 	Address uint16
 	Bus     i2c.Bus
 }
 
+// SWIConfig is the configuration for the single-wire interface over UART.
+type SWIConfig struct {
+	// Port is the serial port connected to the device.
+	Port SerialPort
+
+	// Echo is set when the port receives the tokens it transmits.
+	//
+	// This is the case when TX and RX are both wired to the SWI pin.
+	Echo bool
+}
+
+// LinuxI2CConfig is the configuration for I²C using Linux i2c-dev.
+type LinuxI2CConfig struct {
+	// Path is the path to the i2c-dev device file, e.g. /dev/i2c-1.
+	Path string
+
+	// Address is the I²C target address.
+	Address uint16
+}
+
 type KitType int
 
 const (
@@ -75,6 +163,37 @@ type HIDConfig struct {
 	PacketSize int
 }
 
+// KitSerialConfig is the configuration for the kit protocol over a serial
+// port, such as a USB CDC serial port.
+type KitSerialConfig struct {
+	// Path is the path to the serial port device, e.g. /dev/ttyACM0.
+	Path string
+
+	// BaudRate of the serial port.
+	BaudRate int
+
+	// DevIndex is the kit enumeration index to use unless DevIdentity is set.
+	DevIndex int
+
+	// KitType indicates the underlying interface to use.
+	KitType KitType
+
+	// DevIdentity is the identity of the device.
+	//
+	// For I²C, this is the I²C target address. For the SWI interface, this is
+	// the bus number.
+	DevIdentity uint8
+}
+
+// NetConfig is the configuration for the kit protocol over TCP.
+type NetConfig struct {
+	// Address is the host and port of the KitServer, e.g. rack1:4141.
+	Address string
+
+	// TLS is used to encrypt the connection, unless nil.
+	TLS *tls.Config
+}
+
 // ConfigATECCX08A_I2CDefault returns a default config for an ECCx08A device.
 //
 // TODO: re-think where we put bus, who owns it (who closes, do we have Close?)
@@ -84,6 +203,7 @@ func ConfigATECCX08A_I2CDefault(bus i2c.Bus) IfaceConfig {
 		DeviceType: DeviceATECC608,
 		WakeDelay:  1500 * time.Microsecond,
 		RxRetries:  20,
+		Poll:       DefaultPollConfig,
 		I2C: I2CConfig{
 			Address: 0x60,
 			Bus:     bus,
@@ -91,6 +211,39 @@ func ConfigATECCX08A_I2CDefault(bus i2c.Bus) IfaceConfig {
 	}
 }
 
+// ConfigATECCX08A_SWIDefault returns a default config for an ECCx08A device
+// connected through a serial port.
+//
+// The port should be opened with OpenSerial(path, 230400, 7) or equivalent.
+func ConfigATECCX08A_SWIDefault(port SerialPort) IfaceConfig {
+	return IfaceConfig{
+		IfaceType:  IfaceSWI,
+		DeviceType: DeviceATECC608,
+		WakeDelay:  1500 * time.Microsecond,
+		RxRetries:  20,
+		SWI: SWIConfig{
+			Port: port,
+			Echo: true,
+		},
+	}
+}
+
+// ConfigATECCX08A_LinuxI2CDefault returns a default config for an ECCx08A
+// device connected to the i2c-dev device file at path.
+func ConfigATECCX08A_LinuxI2CDefault(path string) IfaceConfig {
+	return IfaceConfig{
+		IfaceType:  IfaceLinuxI2C,
+		DeviceType: DeviceATECC608,
+		WakeDelay:  1500 * time.Microsecond,
+		RxRetries:  20,
+		Poll:       DefaultPollConfig,
+		LinuxI2C: LinuxI2CConfig{
+			Path:    path,
+			Address: 0x60,
+		},
+	}
+}
+
 const (
 	vendorAtmel = 0x03eb
 
@@ -112,3 +265,31 @@ func ConfigATECCX08A_KitHIDDefault() IfaceConfig {
 		},
 	}
 }
+
+// ConfigATECCX08A_KitSerialDefault returns a configuration for the Kit
+// protocol over a serial port.
+func ConfigATECCX08A_KitSerialDefault(path string) IfaceConfig {
+	return IfaceConfig{
+		IfaceType:  IfaceKitSerial,
+		DeviceType: DeviceATECC608,
+		KitSerial: KitSerialConfig{
+			Path:        path,
+			BaudRate:    115200,
+			DevIndex:    0,
+			KitType:     KitTypeAuto,
+			DevIdentity: 0,
+		},
+	}
+}
+
+// ConfigATECCX08A_NetDefault returns a configuration for the Kit protocol
+// over TCP.
+func ConfigATECCX08A_NetDefault(address string) IfaceConfig {
+	return IfaceConfig{
+		IfaceType:  IfaceNet,
+		DeviceType: DeviceATECC608,
+		Net: NetConfig{
+			Address: address,
+		},
+	}
+}
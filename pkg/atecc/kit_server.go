@@ -0,0 +1,211 @@
+package atecc
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KitServer serves a device over the kit protocol.
+//
+// The server exposes the HAL of the device, allowing it to be used remotely
+// through NewNetDev. Connections are served one at a time, as the device
+// state such as TempKey is shared between them.
+//
+// The server does not authenticate clients, and any client can write, lock or
+// otherwise reconfigure the device. Listen on a loopback address, or require
+// and verify client certificates using TLS.
+type KitServer struct {
+	d  *Dev
+	mu sync.Mutex
+}
+
+// NewKitServer returns a server for the device.
+//
+// The device must not be used while the server is serving connections.
+func NewKitServer(d *Dev) *KitServer {
+	return &KitServer{d: d}
+}
+
+// Serve accepts connections on the listener and serves them.
+//
+// Serve always returns a non-nil error and closes l. Once ctx is done, the
+// listener is closed and ctx.Err() is returned.
+func (s *KitServer) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.ServeConn(ctx, conn)
+		}()
+	}
+}
+
+// ServeConn serves a single connection until the peer disconnects.
+//
+// The device is put into idle state once the connection is done.
+func (s *KitServer) ServeConn(ctx context.Context, conn net.Conn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// close the connection to abort any blocking read once ctx is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		_ = conn.Close()
+	}()
+	defer func() {
+		if s.d.state == deviceStateActive {
+			_ = s.d.hal.Idle()
+			s.d.state = deviceStateIdle
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if _, err := fmt.Fprintf(conn, "%s\n", s.command(ctx, line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// command executes a kit command and returns the reply.
+func (s *KitServer) command(ctx context.Context, line string) string {
+	i := strings.IndexByte(line, '(')
+	if i == -1 || !strings.HasSuffix(line, ")") {
+		return kitReply(ErrParseError, nil)
+	}
+	name, arg := line[:i], line[i+1:len(line)-1]
+
+	kitId := kitIdFromDeviceType(s.d.cfg.DeviceType)
+	prefix := kitId[0:1] + ":"
+	switch {
+	case name == "board:device":
+		return s.device(arg)
+	case name == prefix+"physical:select", name == prefix+"physical:interface":
+		return kitReply(nil, nil)
+	case name == prefix+"w":
+		if err := s.d.hal.Wake(); err != nil {
+			return kitReply(err, nil)
+		}
+		s.d.state = deviceStateActive
+		return kitReply(nil, []byte{0x04, 0x11, 0x33, 0x43})
+	case name == prefix+"i":
+		if err := s.d.hal.Idle(); err != nil {
+			return kitReply(err, nil)
+		}
+		s.d.state = deviceStateIdle
+		return kitReply(nil, nil)
+	case name == prefix+"s":
+		if err := halSleep(s.d.hal); err != nil {
+			return kitReply(err, nil)
+		}
+		s.d.state = deviceStateSleep
+		return kitReply(nil, nil)
+	case name == prefix+"t":
+		return s.transmit(ctx, arg)
+	default:
+		return kitReply(ErrParseError, nil)
+	}
+}
+
+// device replies with the device found at the index.
+//
+// Only a single device is served, found at index 0.
+func (s *KitServer) device(arg string) string {
+	if arg != "00" {
+		return "no_device"
+	}
+
+	iface := "TWI"
+	if s.d.cfg.IfaceType == IfaceSWI {
+		iface = "SWI"
+	}
+	var address uint8
+	switch s.d.cfg.IfaceType {
+	case IfaceI2C:
+		address = uint8(s.d.cfg.I2C.Address << 1)
+	case IfaceLinuxI2C:
+		address = uint8(s.d.cfg.LinuxI2C.Address << 1)
+	}
+	kitId := kitIdFromDeviceType(s.d.cfg.DeviceType)
+	return fmt.Sprintf("%s %s 00(%02X)", kitId, iface, address)
+}
+
+// transmit sends a command packet and replies with the response packet.
+func (s *KitServer) transmit(ctx context.Context, arg string) string {
+	cmd, err := hex.DecodeString(arg)
+	if err != nil || len(cmd) < int(atcaCmdSizeMin) {
+		return kitReply(ErrParseError, nil)
+	}
+	t, err := getExecutionTime(s.d.cfg.DeviceType, s.d.clockDivider, cmd[1])
+	if err != nil {
+		return kitReply(ErrParseError, nil)
+	}
+
+	if _, err := s.d.hal.Write(cmd); err != nil {
+		return kitReply(err, nil)
+	}
+
+	// wait for the operation to finish
+	select {
+	case <-ctx.Done():
+		return kitReply(ctx.Err(), nil)
+	case <-time.After(t):
+	}
+
+	buf := make([]byte, atcaCmdSizeMax)
+	n, err := s.d.hal.Read(buf)
+	return kitReply(err, buf[:n])
+}
+
+// kitReply formats a kit reply with the status and data.
+//
+// Any error which is not a protocol error is reported as a communication
+// error.
+func kitReply(err error, data []byte) string {
+	var status byte
+	switch {
+	case err == nil:
+		status = 0x00
+	case errors.Is(err, ErrParseError):
+		status = 0x03
+	case errors.Is(err, ErrExecution):
+		status = 0x0f
+	default:
+		status = 0xff
+	}
+	if err != nil {
+		data = nil
+	}
+	return fmt.Sprintf("%02X(%s)", status, strings.ToUpper(hex.EncodeToString(data)))
+}
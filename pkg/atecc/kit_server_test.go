@@ -0,0 +1,193 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// serveKit serves the simulated device on localhost.
+func serveKit(t *testing.T, sim *simDevice, tlsConfig *tls.Config) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- NewKitServer(newSimDev(t, sim)).Serve(ctx, l)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-errc; !errors.Is(err, context.Canceled) {
+			t.Errorf("serve: %v", err)
+		}
+	})
+	return l.Addr().String()
+}
+
+// selfSignedTLS returns server and client TLS configs for localhost.
+func selfSignedTLS(t *testing.T) (*tls.Config, *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+	return server, &tls.Config{RootCAs: pool}
+}
+
+func TestKitServer(t *testing.T) {
+	serverTLS, clientTLS := selfSignedTLS(t)
+	testCases := []struct {
+		name   string
+		server *tls.Config
+		client *tls.Config
+	}{
+		{"tcp", nil, nil},
+		{"tls", serverTLS, clientTLS},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			sim := newSimDevice()
+			sim.lock()
+			addr := serveKit(t, sim, tc.server)
+
+			cfg := ConfigATECCX08A_NetDefault(addr)
+			cfg.Net.TLS = tc.client
+			d, err := NewNetDev(ctx, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.Close()
+
+			sn, err := d.SerialNumber(ctx)
+			if err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(sn, simSerialNumber) {
+				t.Errorf("got %x, want %x", sn, simSerialNumber)
+			}
+
+			pub, err := d.GenerateKey(ctx, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			digest := sha256.Sum256([]byte("hello"))
+			sig, err := d.Sign(ctx, 2, digest[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], sig) {
+				t.Error("signature verification failed")
+			}
+
+			// protocol errors are passed on to the client
+			err = d.WriteBytesZone(ctx, ZoneConfig, 0, 32, make([]byte, 32))
+			if !errors.Is(err, ErrExecution) {
+				t.Errorf("got %v, want %v", err, ErrExecution)
+			}
+		})
+	}
+}
+
+func TestKitServerSequential(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	addr := serveKit(t, sim, nil)
+
+	// connections are served one at a time
+	for i := 0; i < 2; i++ {
+		d, err := NewNetDev(ctx, ConfigATECCX08A_NetDefault(addr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.Revision(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// failingIdleHAL is a HAL which fails to idle the device.
+type failingIdleHAL struct {
+	*simDevice
+}
+
+func (h failingIdleHAL) Idle() error {
+	return errors.New("idle failed")
+}
+
+func TestKitServerCommand(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	d, err := New(ctx, failingIdleHAL{sim}, IfaceConfig{DeviceType: DeviceATECC608})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewKitServer(d)
+
+	if got := s.command(ctx, "E:w()"); got != "00(04113343)" {
+		t.Fatalf("wake: got %s", got)
+	}
+	// the state is only changed once the device has been idled
+	if got := s.command(ctx, "E:i()"); got == "00()" {
+		t.Errorf("idle: got %s, want an error", got)
+	}
+	if d.state != deviceStateActive {
+		t.Errorf("got state %v after failed idle, want active", d.state)
+	}
+	if got := s.command(ctx, "E:s()"); got != "00()" {
+		t.Errorf("sleep: got %s", got)
+	}
+	if d.state != deviceStateSleep {
+		t.Errorf("got state %v after sleep, want sleep", d.state)
+	}
+
+	for _, cfg := range []IfaceConfig{
+		ConfigATECCX08A_I2CDefault(nil),
+		ConfigATECCX08A_LinuxI2CDefault("/dev/i2c-1"),
+	} {
+		d.cfg = cfg
+		if got, want := s.device("00"), "ECC608 TWI 00(C0)"; got != want {
+			t.Errorf("%s: got %s, want %s", cfg.IfaceType, got, want)
+		}
+	}
+}
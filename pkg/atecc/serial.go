@@ -0,0 +1,73 @@
+package atecc
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrSerialNotSupported is returned when serial ports are not supported on
+// the platform.
+var ErrSerialNotSupported = errors.New("atecc: serial port support is missing")
+
+// SerialPort is a serial port, such as an UART, used for communication.
+type SerialPort interface {
+	io.ReadWriter
+	// SetBaudRate changes the baud rate of the port.
+	SetBaudRate(baud int) error
+}
+
+// defaultSerialReadTimeout is the time to wait for data before a read fails.
+const defaultSerialReadTimeout = 500 * time.Millisecond
+
+// Serial is a serial port device, such as /dev/ttyUSB0.
+//
+// Serial implements SerialPort.
+type Serial struct {
+	f *os.File
+
+	// ReadTimeout is the time to wait for data before a read fails.
+	ReadTimeout time.Duration
+}
+
+var _ SerialPort = &Serial{}
+
+// OpenSerial opens the serial port device at path.
+//
+// The port is configured in raw mode with the baud rate and data bits
+// supplied, no parity and one stop bit.
+func OpenSerial(path string, baud int, dataBits int) (*Serial, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|serialOpenFlags, 0)
+	if err != nil {
+		return nil, err
+	}
+	s := &Serial{f: f, ReadTimeout: defaultSerialReadTimeout}
+	if err := s.configure(baud, dataBits); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Read reads up to len(p) bytes from the port.
+//
+// Read fails with os.ErrDeadlineExceeded if no data arrives in time.
+func (s *Serial) Read(p []byte) (int, error) {
+	if s.ReadTimeout > 0 {
+		if err := s.f.SetReadDeadline(time.Now().Add(s.ReadTimeout)); err != nil {
+			return 0, err
+		}
+	}
+	return s.f.Read(p)
+}
+
+// Write writes len(p) bytes to the port.
+func (s *Serial) Write(p []byte) (int, error) {
+	return s.f.Write(p)
+}
+
+// Close closes the port.
+func (s *Serial) Close() error {
+	return s.f.Close()
+}
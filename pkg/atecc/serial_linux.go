@@ -0,0 +1,105 @@
+package atecc
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+// serialOpenFlags makes the port non-blocking, allowing read deadlines.
+const serialOpenFlags = syscall.O_NOCTTY | syscall.O_NONBLOCK
+
+func serialSpeed(baud int) (uint32, error) {
+	switch baud {
+	case 9600:
+		return syscall.B9600, nil
+	case 19200:
+		return syscall.B19200, nil
+	case 38400:
+		return syscall.B38400, nil
+	case 57600:
+		return syscall.B57600, nil
+	case 115200:
+		return syscall.B115200, nil
+	case 230400:
+		return syscall.B230400, nil
+	case 460800:
+		return syscall.B460800, nil
+	case 921600:
+		return syscall.B921600, nil
+	default:
+		return 0, errors.New("atecc: unsupported baud rate")
+	}
+}
+
+func serialDataBits(bits int) (uint32, error) {
+	switch bits {
+	case 5:
+		return syscall.CS5, nil
+	case 6:
+		return syscall.CS6, nil
+	case 7:
+		return syscall.CS7, nil
+	case 8:
+		return syscall.CS8, nil
+	default:
+		return 0, errors.New("atecc: unsupported data bits")
+	}
+}
+
+func (s *Serial) configure(baud int, dataBits int) error {
+	speed, err := serialSpeed(baud)
+	if err != nil {
+		return err
+	}
+	size, err := serialDataBits(dataBits)
+	if err != nil {
+		return err
+	}
+
+	// Raw mode, no parity and one stop bit. The speed is carried in the
+	// control flags, which is what the kernel uses for TCSETS.
+	t := syscall.Termios{
+		Cflag: size | speed | syscall.CLOCAL | syscall.CREAD,
+	}
+	t.Cc[syscall.VMIN] = 1
+	return s.setTermios(&t)
+}
+
+// SetBaudRate changes the baud rate of the port.
+func (s *Serial) SetBaudRate(baud int) error {
+	speed, err := serialSpeed(baud)
+	if err != nil {
+		return err
+	}
+
+	var t syscall.Termios
+	if err := s.ioctl(syscall.TCGETS, unsafe.Pointer(&t)); err != nil {
+		return err
+	}
+	t.Cflag = t.Cflag&syscall.CSIZE | speed | syscall.CLOCAL | syscall.CREAD
+	return s.setTermios(&t)
+}
+
+func (s *Serial) setTermios(t *syscall.Termios) error {
+	return s.ioctl(syscall.TCSETS, unsafe.Pointer(t))
+}
+
+func (s *Serial) ioctl(req uintptr, arg unsafe.Pointer) error {
+	rc, err := s.f.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	// Use the raw connection to keep the file descriptor non-blocking.
+	var errno syscall.Errno
+	err = rc.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	} else if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package atecc
+
+const serialOpenFlags = 0
+
+func (s *Serial) configure(baud int, dataBits int) error {
+	return ErrSerialNotSupported
+}
+
+// SetBaudRate changes the baud rate of the port.
+func (s *Serial) SetBaudRate(baud int) error {
+	return ErrSerialNotSupported
+}
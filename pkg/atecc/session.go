@@ -0,0 +1,110 @@
+package atecc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var errKeptAwake = errors.New("atecc: device is kept awake")
+
+// Watchdog durations as configured by ChipMode.
+const (
+	watchdogDurationShort = 1300 * time.Millisecond
+	watchdogDurationLong  = 10 * time.Second
+)
+
+// watchdogDuration returns the time the device stays awake after wake.
+func watchdogDuration(cm interface{ WatchdogDuration() bool }) time.Duration {
+	if cm.WatchdogDuration() {
+		return watchdogDurationLong
+	}
+	return watchdogDurationShort
+}
+
+// awakeFor returns how long the device is guaranteed to stay awake.
+//
+// It returns zero or less if the device is not known to be awake.
+func (d *Dev) awakeFor() time.Duration {
+	if d.state != deviceStateActive {
+		return 0
+	}
+	return d.watchdog - time.Since(d.awake)
+}
+
+// KeepAwake keeps the device awake while fn executes.
+//
+// The device is normally put into idle mode after each command. Commands
+// executed by fn instead run within a single wake window, which guarantees
+// that volatile state such as TempKey is retained between them. The window
+// is limited by the watchdog, configured by ChipMode.WatchdogDuration, and
+// any command that would not finish in time fails with ErrWatchdogTimeout.
+//
+// The device is put into idle mode once the outermost call returns.
+func (d *Dev) KeepAwake(ctx context.Context, fn func(ctx context.Context) error) error {
+	if d.sessions == 0 && d.state == deviceStateActive {
+		// Restart the watchdog to make the full window available.
+		if err := d.hal.Idle(); err != nil {
+			return err
+		}
+		d.state = deviceStateIdle
+	}
+
+	d.sessions++
+	defer func() {
+		d.sessions--
+		if d.sessions == 0 && d.state == deviceStateActive {
+			_ = d.hal.Idle()
+			d.state = deviceStateIdle
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// Idle puts the device into idle mode.
+//
+// The volatile state, such as TempKey, is retained in idle mode and the
+// watchdog is stopped until the device is woken up again.
+func (d *Dev) Idle() error {
+	if d.sessions > 0 {
+		return errKeptAwake
+	}
+	if d.state != deviceStateActive {
+		return nil
+	}
+	if err := d.hal.Idle(); err != nil {
+		return err
+	}
+	d.state = deviceStateIdle
+	return nil
+}
+
+// Sleep puts the device into sleep mode.
+//
+// This is the lowest power mode. All volatile state, such as TempKey, is lost.
+// An error is returned if the HAL does not implement HALSleeper.
+func (d *Dev) Sleep() error {
+	if !canSleep(d.hal) {
+		return errSleepUnsupported
+	}
+	if d.sessions > 0 {
+		return errKeptAwake
+	} else if d.state == deviceStateSleep {
+		return nil
+	}
+
+	// An idle device ignores everything but wake.
+	if d.state != deviceStateActive {
+		if err := d.hal.Wake(); err != nil {
+			return err
+		}
+		d.state = deviceStateActive
+		d.awake = time.Now()
+	}
+	if err := halSleep(d.hal); err != nil {
+		return err
+	}
+	d.state = deviceStateSleep
+	return nil
+}
@@ -0,0 +1,175 @@
+package atecc
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func TestKeepAwake(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.lock()
+	d := newSimDev(t, sim)
+
+	sim.wakes = 0
+	err := d.KeepAwake(ctx, func(ctx context.Context) error {
+		for i := 0; i < 3; i++ {
+			if _, err := d.random(ctx, nil); err != nil {
+				return err
+			}
+			if !sim.awake {
+				t.Error("device idle within session")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.wakes != 1 {
+		t.Errorf("got %d wakes, want 1", sim.wakes)
+	}
+	if sim.awake {
+		t.Error("device awake after session")
+	}
+}
+
+func TestKeepAwakeWatchdog(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.lock()
+	d := newSimDev(t, sim)
+
+	err := d.KeepAwake(ctx, func(ctx context.Context) error {
+		if _, err := d.random(ctx, nil); err != nil {
+			return err
+		}
+
+		// pretend most of the watchdog window has passed
+		d.awake = d.awake.Add(-d.watchdog)
+		_, err := d.random(ctx, nil)
+		return err
+	})
+	if !errors.Is(err, ErrWatchdogTimeout) {
+		t.Errorf("got %v, want %v", err, ErrWatchdogTimeout)
+	}
+
+	// outside of a session the device is woken up again
+	if _, err := d.random(ctx, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSignSession(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	sim.lock()
+	d := newSimDev(t, sim)
+
+	pub, err := d.GenerateKey(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sim.wakes = 0
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := d.Sign(ctx, 2, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.wakes != 1 {
+		t.Errorf("got %d wakes, want 1", sim.wakes)
+	}
+
+	if ok, err := d.VerifyExtern(ctx, digest[:], sig, pub); err != nil || !ok {
+		t.Errorf("verify: %v %v", ok, err)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	d := newSimDev(t, sim)
+
+	err := d.KeepAwake(ctx, func(ctx context.Context) error {
+		return d.Sleep()
+	})
+	if !errors.Is(err, errKeptAwake) {
+		t.Errorf("got %v, want %v", err, errKeptAwake)
+	}
+
+	if err := d.nonceLoad(ctx, nonceTargetTempKey, make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Sleep(); err != nil {
+		t.Fatal(err)
+	}
+	if sim.tempKey != nil {
+		t.Error("TempKey retained during sleep")
+	}
+}
+
+func TestClose(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	d := newSimDev(t, sim)
+
+	if err := d.nonceLoad(ctx, nonceTargetTempKey, make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the idle device is woken up to accept the sleep command
+	wakes := sim.wakes
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.closed {
+		t.Error("transport not closed")
+	}
+	if sim.awake || sim.tempKey != nil {
+		t.Error("device not put to sleep")
+	}
+	if sim.wakes != wakes+1 {
+		t.Errorf("got %d wakes, want %d", sim.wakes, wakes+1)
+	}
+}
+
+// basicHAL hides the optional Sleep and Close methods of a HAL.
+type basicHAL struct {
+	HAL
+}
+
+func TestCloseBasicHAL(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	d, err := New(ctx, basicHAL{sim}, IfaceConfig{DeviceType: DeviceATECC608})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an idle device is left alone
+	if err := d.Idle(); err != nil {
+		t.Fatal(err)
+	}
+	wakes, state := sim.wakes, d.state
+	if err := d.Sleep(); !errors.Is(err, errSleepUnsupported) {
+		t.Errorf("got %v, want %v", err, errSleepUnsupported)
+	}
+	if sim.wakes != wakes || sim.awake || d.state != state {
+		t.Errorf("got %d wakes, awake %t, state %v, want %d wakes, idle, state %v", sim.wakes, sim.awake, d.state, wakes, state)
+	}
+
+	if err := d.nonceLoad(ctx, nonceTargetTempKey, make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the device is idled instead, retaining TempKey
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if sim.closed || sim.awake || sim.tempKey == nil {
+		t.Errorf("got closed %t, awake %t, TempKey %x", sim.closed, sim.awake, sim.tempKey)
+	}
+}
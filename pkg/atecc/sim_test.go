@@ -0,0 +1,471 @@
+package atecc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+// Status codes returned by the simulated device.
+const (
+	simStatusOK        = 0x00
+	simStatusVerify    = 0x01
+	simStatusParse     = 0x03
+	simStatusExecution = 0x0f
+)
+
+// simSerialNumber is the serial number of the simulated device.
+var simSerialNumber = []byte{0x01, 0x23, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0xee}
+
+var (
+	errSimAsleep = errors.New("sim: device is asleep")
+	errSimBusy   = errors.New("sim: device is busy")
+)
+
+// simDevice emulates an ATECC608 for testing the driver without hardware.
+//
+// It implements HAL on the packet level and supports a subset of the commands
+// with enough of the access rules to exercise the driver.
+type simDevice struct {
+	revision [4]byte
+	config   [zoneSizeConfig]byte
+	otp      [zoneSizeOTP]byte
+	data     [16][]byte
+	keys     [16]*ecdsa.PrivateKey
+
+	tempKey   []byte
+	msgDigBuf []byte
+
+	awake  bool
+	wakes  int
+	closed bool
+	resp   []byte
+
+	// opcodes contains all executed opcodes, in order.
+	opcodes []uint8
+
+	// ca2 emulates the memory of an ECC204 instead, if set.
+	ca2 *simCA2
+
+	// busy is the time it takes to execute a command. The response can not
+	// be read until the command has finished.
+	busy      time.Duration
+	busyUntil time.Time
+}
+
+func newSimDevice() *simDevice {
+	s := &simDevice{
+		revision: [4]byte{0x00, 0x00, 0x60, 0x02},
+	}
+	copy(s.config[:], simSerialNumber[:4])
+	copy(s.config[4:], s.revision[:])
+	copy(s.config[8:], simSerialNumber[4:])
+	s.config[13] = 0x01 // AESEnable
+	s.config[14] = 0x01 // I2CEnable
+	copy(s.config[ateccconf.PermanentOffset608:], ateccconf.Default608)
+	for i := range s.data {
+		size, _ := getZoneSize(DeviceATECC608, ZoneData, uint16(i))
+		s.data[i] = make([]byte, size)
+	}
+	return s
+}
+
+// newSimDev returns a Dev communicating with the simulated device.
+func newSimDev(t testing.TB, sim *simDevice) *Dev {
+	t.Helper()
+	d, err := New(context.Background(), sim, IfaceConfig{
+		DeviceType: DeviceATECC608,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func (s *simDevice) configLocked() bool {
+	return s.config[87] != byte(ateccconf.LockStateUnlocked)
+}
+
+func (s *simDevice) dataLocked() bool {
+	return s.config[86] != byte(ateccconf.LockStateUnlocked)
+}
+
+func (s *simDevice) slotConfig(slot int) ateccconf.SlotConfig {
+	return ateccconf.SlotConfig{Bits1: s.config[20+2*slot], Bits2: s.config[21+2*slot]}
+}
+
+func (s *simDevice) keyConfig(slot int) ateccconf.KeyConfig {
+	return ateccconf.KeyConfig{Bits1: s.config[96+2*slot], Bits2: s.config[97+2*slot]}
+}
+
+func (s *simDevice) slotLocked(slot int) bool {
+	return s.config[88+slot/8]&(1<<(slot%8)) == 0
+}
+
+// lock locks the config and data zones without verifying any CRC.
+func (s *simDevice) lock() {
+	s.config[86] = byte(ateccconf.LockStateLocked)
+	s.config[87] = byte(ateccconf.LockStateLocked)
+}
+
+func (s *simDevice) Wake() error {
+	s.awake = true
+	s.wakes++
+	return nil
+}
+
+func (s *simDevice) Idle() error {
+	if !s.awake {
+		return errSimAsleep
+	}
+	s.awake = false
+	return nil
+}
+
+func (s *simDevice) Sleep() error {
+	if !s.awake {
+		return errSimAsleep
+	}
+	s.awake = false
+	s.tempKey = nil
+	s.msgDigBuf = nil
+	return nil
+}
+
+func (s *simDevice) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *simDevice) Write(p []byte) (int, error) {
+	if !s.awake {
+		return 0, errSimAsleep
+	}
+	if len(p) < int(atcaCmdSizeMin) || int(p[0]) != len(p) {
+		s.resp = simResponse(simStatusParse, nil)
+		return len(p), nil
+	}
+	crc := binary.LittleEndian.Uint16(p[len(p)-2:])
+	if crc16(p[:len(p)-2]) != crc {
+		s.resp = simResponse(0xff, nil)
+		return len(p), nil
+	}
+
+	opcode, param1 := p[1], p[2]
+	param2 := binary.LittleEndian.Uint16(p[3:5])
+	data := p[5 : len(p)-2]
+	s.opcodes = append(s.opcodes, opcode)
+
+	status, out := s.execute(opcode, param1, param2, data)
+	s.resp = simResponse(status, out)
+	s.busyUntil = time.Now().Add(s.busy)
+	return len(p), nil
+}
+
+func (s *simDevice) Read(p []byte) (int, error) {
+	if !s.awake {
+		return 0, errSimAsleep
+	}
+	if time.Now().Before(s.busyUntil) {
+		return 0, errSimBusy
+	}
+	if len(s.resp) > len(p) {
+		return 0, ErrRecvBuffer
+	}
+	n := copy(p, s.resp)
+	s.resp = nil
+	return n, nil
+}
+
+// simResponse encodes a response packet with size, payload and crc.
+//
+// A status only response is returned if there is no payload.
+func simResponse(status byte, out []byte) []byte {
+	if status != simStatusOK || len(out) == 0 {
+		out = []byte{status}
+	}
+	b := []byte{byte(len(out) + 3)}
+	b = append(b, out...)
+	return binary.LittleEndian.AppendUint16(b, crc16(b))
+}
+
+func (s *simDevice) execute(opcode, param1 uint8, param2 uint16, data []byte) (byte, []byte) {
+	if s.ca2 != nil {
+		if status, out, ok := s.ca2.execute(opcode, param1, param2, data); ok {
+			return status, out
+		}
+	}
+
+	switch opcode {
+	case atcaInfo:
+		return simStatusOK, s.revision[:]
+	case atcaRead:
+		return s.read(param1, param2)
+	case atcaWrite:
+		return s.write(param1, param2, data)
+	case atcaUpdateExtra:
+		return s.updateExtra(param1, param2)
+	case atcaLock:
+		return s.lockCommand(param1, param2)
+	case atcaRandom:
+		var b [32]byte
+		_, _ = rand.Read(b[:])
+		return simStatusOK, b[:]
+	case atcaNonce:
+		return s.nonce(param1, data)
+	case atcaGenKey:
+		return s.genKey(param1, param2)
+	case atcaPrivWrite:
+		return s.privWrite(param1, param2, data)
+	case atcaSign:
+		return s.sign(param1, param2)
+	case atcaVerify:
+		return s.verify(param1, data)
+	default:
+		return simStatusParse, nil
+	}
+}
+
+// zoneBuffer returns the memory and byte offset addressed by the command.
+func (s *simDevice) zoneBuffer(param1 uint8, param2 uint16) ([]byte, Zone, int, int) {
+	zone := Zone(param1 & 0x03)
+	size := atcaWordSize
+	if param1&atcaZoneReadWrite32 != 0 {
+		size = atcaBlockSize
+	}
+
+	word := int(param2 & 0x07)
+	switch zone {
+	case ZoneConfig:
+		block := int(param2>>3) & 0x1f
+		return s.config[:], zone, block*atcaBlockSize + word*atcaWordSize, size
+	case ZoneOTP:
+		block := int(param2>>3) & 0x1f
+		return s.otp[:], zone, block*atcaBlockSize + word*atcaWordSize, size
+	case ZoneData:
+		slot := int(param2>>3) & 0x0f
+		block := int(param2 >> 8)
+		return s.data[slot], zone, block*atcaBlockSize + word*atcaWordSize, size
+	default:
+		return nil, zone, 0, 0
+	}
+}
+
+func (s *simDevice) read(param1 uint8, param2 uint16) (byte, []byte) {
+	buf, zone, offset, size := s.zoneBuffer(param1, param2)
+	if buf == nil || offset+size > len(buf) {
+		return simStatusParse, nil
+	}
+	switch zone {
+	case ZoneOTP:
+		if !s.dataLocked() {
+			return simStatusExecution, nil
+		}
+	case ZoneData:
+		slot := int(param2>>3) & 0x0f
+		if !s.dataLocked() || s.slotConfig(slot).IsSecret() {
+			return simStatusExecution, nil
+		}
+	}
+	return simStatusOK, buf[offset : offset+size]
+}
+
+func (s *simDevice) write(param1 uint8, param2 uint16, data []byte) (byte, []byte) {
+	buf, zone, offset, size := s.zoneBuffer(param1, param2)
+	if buf == nil || offset >= len(buf) || len(data) < size {
+		return simStatusParse, nil
+	}
+	// the bytes of a block past the end of a data slot are ignored
+	if zone != ZoneData && offset+size > len(buf) {
+		return simStatusParse, nil
+	}
+	switch zone {
+	case ZoneConfig:
+		if s.configLocked() || offset < ateccconf.PermanentOffset608 {
+			return simStatusExecution, nil
+		}
+		if offset <= ateccconf.LockOffset && ateccconf.LockOffset < offset+size {
+			return simStatusExecution, nil
+		}
+	case ZoneOTP:
+		if s.dataLocked() || size != atcaBlockSize {
+			return simStatusExecution, nil
+		}
+	case ZoneData:
+		slot := int(param2>>3) & 0x0f
+		if !s.configLocked() {
+			return simStatusExecution, nil
+		}
+		if !s.dataLocked() {
+			if size != atcaBlockSize {
+				return simStatusExecution, nil
+			}
+		} else if s.slotLocked(slot) || s.slotConfig(slot).Bits2&0xf0 != 0 {
+			return simStatusExecution, nil
+		}
+	}
+	copy(buf[offset:], data[:size])
+	return simStatusOK, nil
+}
+
+func (s *simDevice) updateExtra(mode uint8, value uint16) (byte, []byte) {
+	index := 84 + int(mode&0x01)
+	if s.config[index] != 0 {
+		return simStatusExecution, nil
+	}
+	s.config[index] = byte(value)
+	return simStatusOK, nil
+}
+
+func (s *simDevice) lockCommand(mode uint8, crc uint16) (byte, []byte) {
+	var content []byte
+	switch mode & 0x03 {
+	case uint8(lockZoneConfig):
+		if s.configLocked() {
+			return simStatusExecution, nil
+		}
+		content = s.config[:]
+	case uint8(lockZoneData):
+		if !s.configLocked() || s.dataLocked() {
+			return simStatusExecution, nil
+		}
+		for _, slot := range s.data {
+			content = append(content, slot...)
+		}
+		content = append(content, s.otp[:]...)
+	case uint8(lockZoneDataSlot):
+		slot := int(mode>>2) & 0x0f
+		if !s.dataLocked() || s.slotLocked(slot) || !s.keyConfig(slot).Lockable() {
+			return simStatusExecution, nil
+		}
+		content = s.data[slot]
+	default:
+		return simStatusParse, nil
+	}
+
+	if mode&uint8(lockModeNoCRC) == 0 && crc16(content) != crc {
+		return simStatusExecution, nil
+	}
+
+	switch mode & 0x03 {
+	case uint8(lockZoneConfig):
+		s.config[87] = byte(ateccconf.LockStateLocked)
+	case uint8(lockZoneData):
+		s.config[86] = byte(ateccconf.LockStateLocked)
+	case uint8(lockZoneDataSlot):
+		slot := int(mode>>2) & 0x0f
+		s.config[88+slot/8] &^= 1 << (slot % 8)
+	}
+	return simStatusOK, nil
+}
+
+func (s *simDevice) nonce(mode uint8, data []byte) (byte, []byte) {
+	if mode&0x03 != uint8(nonceModePassthrough) {
+		return simStatusParse, nil
+	}
+	value := append([]byte(nil), data...)
+	switch nonceTarget(mode & 0xc0) {
+	case nonceTargetTempKey:
+		s.tempKey = value
+	case nonceTargetMsgDigBuf:
+		s.msgDigBuf = value
+	default:
+		return simStatusParse, nil
+	}
+	return simStatusOK, nil
+}
+
+func (s *simDevice) genKey(mode uint8, slot uint16) (byte, []byte) {
+	if slot > 15 || !s.keyConfig(int(slot)).Private() {
+		return simStatusExecution, nil
+	}
+	switch mode {
+	case genKeyModePrivate:
+		if s.dataLocked() && !s.slotConfig(int(slot)).WriteConfig().GenKeyEnabled {
+			return simStatusExecution, nil
+		}
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return simStatusExecution, nil
+		}
+		s.keys[slot] = key
+	case genKeyModePublic:
+		if s.keys[slot] == nil {
+			return simStatusExecution, nil
+		}
+	default:
+		return simStatusParse, nil
+	}
+
+	var pub [64]byte
+	s.keys[slot].X.FillBytes(pub[:32])
+	s.keys[slot].Y.FillBytes(pub[32:])
+	return simStatusOK, pub[:]
+}
+
+func (s *simDevice) privWrite(mode uint8, slot uint16, data []byte) (byte, []byte) {
+	if mode != uint8(privWriteModeClear) || slot > 15 || len(data) != 68 {
+		return simStatusParse, nil
+	}
+	if !s.configLocked() || s.dataLocked() || !s.keyConfig(int(slot)).Private() {
+		return simStatusExecution, nil
+	}
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(data[4:36])}
+	key.Curve = elliptic.P256()
+	key.X, key.Y = key.Curve.ScalarBaseMult(data[4:36])
+	s.keys[slot] = key
+	return simStatusOK, nil
+}
+
+func (s *simDevice) message(source uint8) []byte {
+	if source&uint8(signSourceMsgDigBuf) != 0 {
+		return s.msgDigBuf
+	}
+	return s.tempKey
+}
+
+func (s *simDevice) sign(mode uint8, slot uint16) (byte, []byte) {
+	if mode&uint8(signModeExternal) == 0 || slot > 15 || s.keys[slot] == nil {
+		return simStatusExecution, nil
+	}
+	msg := s.message(mode)
+	if len(msg) != 32 {
+		return simStatusExecution, nil
+	}
+	r, ss, err := ecdsa.Sign(rand.Reader, s.keys[slot], msg)
+	if err != nil {
+		return simStatusExecution, nil
+	}
+	var sig [64]byte
+	r.FillBytes(sig[:32])
+	ss.FillBytes(sig[32:])
+	return simStatusOK, sig[:]
+}
+
+func (s *simDevice) verify(mode uint8, data []byte) (byte, []byte) {
+	if verifyMode(mode&0x07) != verifyModeExternal || len(data) != 128 {
+		return simStatusParse, nil
+	}
+	msg := s.message(mode)
+	if len(msg) != 32 {
+		return simStatusExecution, nil
+	}
+	var r, ss, x, y big.Int
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     x.SetBytes(data[64:96]),
+		Y:     y.SetBytes(data[96:]),
+	}
+	if !ecdsa.Verify(pub, msg, r.SetBytes(data[:32]), ss.SetBytes(data[32:64])) {
+		return simStatusVerify, nil
+	}
+	return simStatusOK, nil
+}
@@ -0,0 +1,201 @@
+package atecc
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+// Slot is a slot of the data zone, read and written at byte offsets.
+//
+// Reads and writes are split into the block and word accesses of the device.
+// Before the data zone is locked, the slot can not be read and writes must be
+// aligned to 32 byte blocks, except for a last block extending past the end of
+// the slot. Once locked, writes must be aligned to 4 byte words and the slot
+// must not be locked.
+//
+// On ATECC608 devices, the slot configuration is also checked to permit reads
+// and writes in the clear. Encrypted reads and writes are not supported.
+type Slot struct {
+	ctx  context.Context
+	d    *Dev
+	slot uint16
+	size int
+}
+
+var (
+	_ io.ReaderAt = (*Slot)(nil)
+	_ io.WriterAt = (*Slot)(nil)
+)
+
+// Slot returns the slot of the data zone.
+//
+// The context is used for all reads and writes of the slot.
+func (d *Dev) Slot(ctx context.Context, slot int) (*Slot, error) {
+	if slot < 0 || slot > 0xffff {
+		return nil, fmt.Errorf("atecc: invalid slot %d", slot)
+	}
+	size, err := getZoneSize(d.cfg.DeviceType, ZoneData, uint16(slot))
+	if err != nil {
+		return nil, err
+	}
+	return &Slot{ctx: ctx, d: d, slot: uint16(slot), size: size}, nil
+}
+
+// Size returns the size of the slot in bytes.
+func (s *Slot) Size() int64 {
+	return int64(s.size)
+}
+
+// ReadAt reads len(p) bytes of the slot starting at offset off.
+//
+// It returns io.EOF if fewer bytes were read because the end of the slot was
+// reached.
+func (s *Slot) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("atecc: negative offset %d in slot %d", off, s.slot)
+	}
+	if off >= int64(s.size) {
+		return 0, io.EOF
+	}
+
+	var eof error
+	if remaining := int64(s.size) - off; int64(len(p)) > remaining {
+		p, eof = p[:remaining], io.EOF
+	}
+	if err := s.checkRead(); err != nil {
+		return 0, err
+	}
+	n, err := s.d.readBytesZone(s.ctx, ZoneData, s.slot, int(off), p)
+	if err != nil {
+		return n, err
+	}
+	return n, eof
+}
+
+// WriteAt writes p to the slot starting at offset off.
+//
+// Writes extending past the end of the slot are rejected without writing
+// anything.
+func (s *Slot) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(s.size) {
+		return 0, fmt.Errorf("atecc: write of %d bytes at offset %d is outside of slot %d (%d bytes)", len(p), off, s.slot, s.size)
+	}
+	if err := s.checkWrite(int(off), len(p)); err != nil {
+		return 0, err
+	}
+	return s.d.writeBytesZone(s.ctx, ZoneData, s.slot, int(off), p)
+}
+
+// slotState is the lock state and access of a slot, as read from the config
+// zone.
+type slotState struct {
+	configLocked bool
+	dataLocked   bool
+	slotLocked   bool
+	// explanation is only set on ATECC608 devices.
+	explanation *ateccconf.SlotExplanation
+}
+
+func (s *Slot) state() (*slotState, error) {
+	ctx, d := s.ctx, s.d
+	if d.cfg.DeviceType.isCA2() {
+		// data slots are locked individually
+		locked, err := d.isSlotLockedCA2(ctx, ZoneData, s.slot)
+		if err != nil {
+			return nil, err
+		}
+		return &slotState{configLocked: true, dataLocked: true, slotLocked: locked}, nil
+	}
+
+	config, err := d.ReadConfigZone(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch d.cfg.DeviceType {
+	case DeviceATSHA204:
+		var conf ateccconf.Config204
+		if err := ateccconf.Unmarshal(config, &conf); err != nil {
+			return nil, err
+		}
+		return &slotState{
+			configLocked: conf.LockConfig.IsLocked(),
+			dataLocked:   conf.LockValue.IsLocked(),
+		}, nil
+	case DeviceATECC508:
+		var conf ateccconf.Config508
+		if err := ateccconf.Unmarshal(config, &conf); err != nil {
+			return nil, err
+		}
+		return &slotState{
+			configLocked: conf.LockConfig.IsLocked(),
+			dataLocked:   conf.LockValue.IsLocked(),
+			slotLocked:   conf.SlotLocked.IsLocked(int(s.slot)),
+		}, nil
+	default:
+		var conf ateccconf.Config608
+		if err := ateccconf.Unmarshal(config, &conf); err != nil {
+			return nil, err
+		}
+		e, err := ateccconf.Explain(&conf, int(s.slot))
+		if err != nil {
+			return nil, err
+		}
+		return &slotState{
+			configLocked: conf.LockConfig.IsLocked(),
+			dataLocked:   conf.LockValue.IsLocked(),
+			slotLocked:   conf.SlotLocked.IsLocked(int(s.slot)),
+			explanation:  e,
+		}, nil
+	}
+}
+
+// checkRead returns an error if the slot can not be read in the clear.
+func (s *Slot) checkRead() error {
+	st, err := s.state()
+	if err != nil {
+		return err
+	}
+	if !st.dataLocked {
+		return fmt.Errorf("atecc: slot %d can not be read before the data zone is locked", s.slot)
+	}
+	if e := st.explanation; e != nil && e.Read.Mode != ateccconf.AccessClear {
+		return fmt.Errorf("atecc: slot %d can not be read in the clear (read: %s)", s.slot, e.Read)
+	}
+	return nil
+}
+
+// checkWrite returns an error if the write is not permitted in the current
+// lock state of the slot.
+func (s *Slot) checkWrite(off, n int) error {
+	if s.d.cfg.DeviceType.isCA2() {
+		if off%ca2PageSize != 0 || n%ca2PageSize != 0 {
+			return fmt.Errorf("atecc: writes to slot %d must be multiples of %d bytes", s.slot, ca2PageSize)
+		}
+	}
+
+	st, err := s.state()
+	if err != nil {
+		return err
+	}
+	switch {
+	case !st.configLocked:
+		return fmt.Errorf("atecc: slot %d can not be written before the config zone is locked", s.slot)
+	case !st.dataLocked:
+		if off%atcaBlockSize != 0 || (n%atcaBlockSize != 0 && off+n != s.size) {
+			return fmt.Errorf("atecc: writes to slot %d must be %d byte blocks before the data zone is locked", s.slot, atcaBlockSize)
+		}
+	case st.slotLocked:
+		return fmt.Errorf("atecc: slot %d is locked", s.slot)
+	default:
+		if off%atcaWordSize != 0 || n%atcaWordSize != 0 {
+			return fmt.Errorf("atecc: writes to slot %d must be multiples of %d bytes", s.slot, atcaWordSize)
+		}
+		if e := st.explanation; e != nil && e.Write.Mode != ateccconf.AccessClear && e.Write.Mode != ateccconf.AccessPubInvalid {
+			return fmt.Errorf("atecc: slot %d can not be written in the clear (write: %s)", s.slot, e.Write)
+		}
+	}
+	return nil
+}
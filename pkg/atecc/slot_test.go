@@ -0,0 +1,128 @@
+package atecc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+func TestSlotSize(t *testing.T) {
+	ctx := context.Background()
+	d := newSimDev(t, newSimDevice())
+
+	for slot, want := range map[int]int64{0: 36, 7: 36, 8: 416, 9: 72, 15: 72} {
+		s, err := d.Slot(ctx, slot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Size(); got != want {
+			t.Errorf("slot %d: got size %d, want %d", slot, got, want)
+		}
+	}
+	if _, err := d.Slot(ctx, 16); err == nil {
+		t.Error("expected error for invalid slot")
+	}
+}
+
+func TestSlot(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimDevice()
+	d := newSimDev(t, sim)
+
+	slot8, err := d.Slot(ctx, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slot10, err := d.Slot(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]byte, slot8.Size())
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if _, err := slot8.WriteAt(data, 0); err == nil {
+		t.Error("expected error for write before config lock")
+	}
+
+	// only blocks may be written before the data zone is locked
+	sim.config[87] = byte(ateccconf.LockStateLocked)
+	if n, err := slot8.WriteAt(data, 0); err != nil || n != len(data) {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if _, err := slot10.WriteAt(data[:72], 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slot8.WriteAt(data[:4], 32); err == nil {
+		t.Error("expected error for word write before data lock")
+	}
+	if _, err := slot10.WriteAt(data[:32], 64); err == nil {
+		t.Error("expected error for write past the end of the slot")
+	}
+	if _, err := slot8.ReadAt(data[:4], 0); err == nil {
+		t.Error("expected error for read before data lock")
+	}
+
+	sim.config[86] = byte(ateccconf.LockStateLocked)
+	got := make([]byte, slot8.Size())
+	if n, err := slot8.ReadAt(got, 0); err != nil || n != len(got) {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("read data does not match the written data")
+	}
+
+	// the last block of slot 10 is read a word at a time
+	got = make([]byte, 12)
+	if _, err := slot10.ReadAt(got, 60); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data[60:72]) {
+		t.Errorf("got %x, want %x", got, data[60:72])
+	}
+
+	got = make([]byte, 32)
+	if n, err := slot8.ReadAt(got, 400); err != io.EOF || n != 16 {
+		t.Errorf("got %d, %v, want 16, %v", n, err, io.EOF)
+	}
+	if n, err := slot8.ReadAt(got, 416); err != io.EOF || n != 0 {
+		t.Errorf("got %d, %v, want 0, %v", n, err, io.EOF)
+	}
+	if _, err := slot8.ReadAt(got, -1); err == nil {
+		t.Error("expected error for negative offset")
+	}
+
+	// words may be written once the data zone is locked
+	word := []byte{0xde, 0xad, 0xbe, 0xef}
+	if _, err := slot8.WriteAt(word, 300); err != nil {
+		t.Fatal(err)
+	}
+	r := io.NewSectionReader(slot8, 300, 4)
+	if got, err := io.ReadAll(r); err != nil || !bytes.Equal(got, word) {
+		t.Errorf("got %x, %v, want %x", got, err, word)
+	}
+	if _, err := slot8.WriteAt(word, 302); err == nil {
+		t.Error("expected error for unaligned write")
+	}
+
+	// private keys are never read or written in the clear
+	slot0, err := d.Slot(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slot0.ReadAt(got[:4], 0); err == nil {
+		t.Error("expected error for reading a private key")
+	}
+	if _, err := slot0.WriteAt(word, 0); err == nil {
+		t.Error("expected error for writing a private key")
+	}
+
+	sim.config[89] &^= 0x01 // slot 8
+	if _, err := slot8.WriteAt(word, 0); err == nil {
+		t.Error("expected error for write to a locked slot")
+	}
+}
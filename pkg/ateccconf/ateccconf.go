@@ -1,3 +1,9 @@
+// Package ateccconf encodes, decodes and describes the configuration zones of
+// ATECC608, ATECC508, ATSHA204 and ECC204 class devices.
+//
+// The bitfield types are built using their With setters. The setters panic if
+// a value does not fit in its field, rather than truncating it into another
+// key or mode of a configuration which may be locked irreversibly.
 package ateccconf
 
 import (
@@ -5,10 +11,10 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/bits"
 )
 
-// TODO: add easier handling of bits
-
 // Default608 is an example configuration for ATECC608A.
 //
 // First 16 bytes as expected from a normal configuration is not included.
@@ -32,6 +38,84 @@ func DefaultConfig608() *Config608 {
 	return &conf
 }
 
+// setBits returns b with the field in mask set to v.
+//
+// The value is shifted into the position of the mask. It panics if v does not
+// fit in the field, as truncating it would silently configure another value.
+func setBits[T ~uint8 | ~uint16](b byte, mask byte, v T) byte {
+	if err := checkBits("value", mask, v); err != nil {
+		panic(err)
+	}
+	return b&^mask | byte(v)<<bits.TrailingZeros8(mask)&mask
+}
+
+// setFlag returns b with the bits in mask set or cleared.
+func setFlag(b byte, mask byte, on bool) byte {
+	if on {
+		return b | mask
+	}
+	return b &^ mask
+}
+
+// unmarshalBits decodes the exploded JSON form of a bitfield.
+//
+// Unknown fields are rejected, as they would otherwise be silently dropped.
+func unmarshalBits(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// checkBits returns an error if v does not fit in the field of mask.
+func checkBits[T ~uint8 | ~uint16](name string, mask byte, v T) error {
+	if max := mask >> bits.TrailingZeros8(mask); uint16(v) > uint16(max) {
+		return fmt.Errorf("atecc: %s out of range: %d > %d", name, v, max)
+	}
+	return nil
+}
+
+// enum is a named value of a bitfield.
+type enum interface {
+	~uint8
+	String() string
+}
+
+// marshalEnum encodes the name of v if known, or the raw value otherwise.
+//
+// This keeps reserved and undocumented values intact.
+func marshalEnum[T enum](v T, known []T) ([]byte, error) {
+	for _, k := range known {
+		if v == k {
+			return json.Marshal(v.String())
+		}
+	}
+	return json.Marshal(uint8(v))
+}
+
+// unmarshalEnum decodes either the name of a known value or a raw value.
+func unmarshalEnum[T enum](data []byte, v *T, known []T) error {
+	if len(data) == 0 || data[0] != '"' {
+		var n uint8
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		*v = T(n)
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for _, k := range known {
+		if k.String() == name {
+			*v = k
+			return nil
+		}
+	}
+	return fmt.Errorf("atecc: unknown %T: %q", *v, name)
+}
+
 type AESEnable struct {
 	// Bits contains of
 	// * enabled 1
@@ -52,6 +136,11 @@ func (a AESEnable) Reserved() byte {
 	return a.Bits >> 1
 }
 
+func (a AESEnable) WithEnabled(on bool) AESEnable {
+	a.Bits = setFlag(a.Bits, 0x01, on)
+	return a
+}
+
 func (a AESEnable) MarshalJSON() ([]byte, error) {
 	return json.Marshal(aesEnabledBits{
 		Enabled:  a.Enabled(),
@@ -59,6 +148,18 @@ func (a AESEnable) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (a *AESEnable) UnmarshalJSON(data []byte) error {
+	var b aesEnabledBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := checkBits("reserved", 0xfe, b.Reserved); err != nil {
+		return err
+	}
+	*a = AESEnable{Bits: setBits(0, 0xfe, b.Reserved)}.WithEnabled(b.Enabled)
+	return nil
+}
+
 type I2CEnable struct {
 	// Bits contains of
 	// * Enabled 1
@@ -79,6 +180,11 @@ func (i I2CEnable) Reserved() byte {
 	return i.Bits >> 1
 }
 
+func (i I2CEnable) WithEnabled(on bool) I2CEnable {
+	i.Bits = setFlag(i.Bits, 0x01, on)
+	return i
+}
+
 func (i I2CEnable) MarshalJSON() ([]byte, error) {
 	return json.Marshal(i2cEnableBits{
 		Enabled:  i.Enabled(),
@@ -86,6 +192,18 @@ func (i I2CEnable) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (i *I2CEnable) UnmarshalJSON(data []byte) error {
+	var b i2cEnableBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := checkBits("reserved", 0xfe, b.Reserved); err != nil {
+		return err
+	}
+	*i = I2CEnable{Bits: setBits(0, 0xfe, b.Reserved)}.WithEnabled(b.Enabled)
+	return nil
+}
+
 type CountMatch struct {
 	// Bits contains of:
 	// * Enabled       1
@@ -112,6 +230,16 @@ func (cm CountMatch) Key() byte {
 	return (cm.Bits & 0xf0) >> 4
 }
 
+func (cm CountMatch) WithEnabled(on bool) CountMatch {
+	cm.Bits = setFlag(cm.Bits, 0x01, on)
+	return cm
+}
+
+func (cm CountMatch) WithKey(key byte) CountMatch {
+	cm.Bits = setBits(cm.Bits, 0xf0, key)
+	return cm
+}
+
 func (cm CountMatch) MarshalJSON() ([]byte, error) {
 	return json.Marshal(countMatchBits{
 		Enabled:  cm.Enabled(),
@@ -120,6 +248,23 @@ func (cm CountMatch) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (cm *CountMatch) UnmarshalJSON(data []byte) error {
+	var b countMatchBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("reserved", 0x0e, b.Reserved),
+		checkBits("key", 0xf0, b.Key),
+	); err != nil {
+		return err
+	}
+	*cm = CountMatch{Bits: setBits(0, 0x0e, b.Reserved)}.
+		WithEnabled(b.Enabled).
+		WithKey(b.Key)
+	return nil
+}
+
 const (
 	// ChipModeOffset is the byte offset within the configuration zone
 	ChipModeOffset = 19
@@ -127,6 +272,19 @@ const (
 	// PermanentOffset608 is the device offset which cannot be written to.
 	PermanentOffset608 = 16
 
+	// ConfigSize608 is the size of the ATECC608 configuration zone.
+	ConfigSize608 = 128
+	// ConfigSize508 is the size of the ATECC508 configuration zone.
+	ConfigSize508 = 128
+	// ConfigSize204 is the size of the ATSHA204 configuration zone.
+	ConfigSize204 = 88
+
+	// ConfigSizeCA2 is the size of the ECC204, SHA104, SHA105 and TA010
+	// configuration zone.
+	ConfigSizeCA2 = 64
+	// ConfigSubzoneSizeCA2 is the size of each configuration subzone.
+	ConfigSubzoneSizeCA2 = 16
+
 	LockOffsetBlock = 2
 	LockOffsetWord  = 5
 
@@ -145,6 +303,8 @@ const (
 	ClockDividerM2 = ClockDivider(0x68 >> 3)
 )
 
+var clockDividers = []ClockDivider{ClockDividerM0, ClockDividerM1, ClockDividerM2}
+
 func (c ClockDivider) String() string {
 	switch c {
 	case ClockDividerM0:
@@ -159,7 +319,11 @@ func (c ClockDivider) String() string {
 }
 
 func (c ClockDivider) MarshalJSON() ([]byte, error) {
-	return json.Marshal(c.String())
+	return marshalEnum(c, clockDividers)
+}
+
+func (c *ClockDivider) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, c, clockDividers)
 }
 
 type ChipMode608 struct {
@@ -197,6 +361,26 @@ func (cm ChipMode608) ClockDivider() ClockDivider {
 	return ClockDivider(cm.Bits & 0xf8 >> 3)
 }
 
+func (cm ChipMode608) WithUserExtraAdd(on bool) ChipMode608 {
+	cm.Bits = setFlag(cm.Bits, 0x01, on)
+	return cm
+}
+
+func (cm ChipMode608) WithTTLEnabled(on bool) ChipMode608 {
+	cm.Bits = setFlag(cm.Bits, 0x02, on)
+	return cm
+}
+
+func (cm ChipMode608) WithWatchdogDuration(long bool) ChipMode608 {
+	cm.Bits = setFlag(cm.Bits, 0x04, long)
+	return cm
+}
+
+func (cm ChipMode608) WithClockDivider(div ClockDivider) ChipMode608 {
+	cm.Bits = setBits(cm.Bits, 0xf8, div)
+	return cm
+}
+
 func (cm ChipMode608) MarshalJSON() ([]byte, error) {
 	return json.Marshal(chipMode608Bits{
 		UserExtraAdd:     cm.UserExtraAdd(),
@@ -206,6 +390,96 @@ func (cm ChipMode608) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (cm *ChipMode608) UnmarshalJSON(data []byte) error {
+	var b chipMode608Bits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := checkBits("clock_divider", 0xf8, b.ClockDivider); err != nil {
+		return err
+	}
+	*cm = ChipMode608{}.
+		WithUserExtraAdd(b.UserExtraAdd).
+		WithTTLEnabled(b.TTLEnabled).
+		WithWatchdogDuration(b.WatchdogDuration).
+		WithClockDivider(b.ClockDivider)
+	return nil
+}
+
+type ChipMode508 struct {
+	// Bits consists of:
+	// * SelectorMode     1
+	//   1 Selector can only be updated if it is zero
+	// * TTLenable        1
+	//   0 I/O’s use Fixed Reference mode
+	// * WatchdogDuration 1
+	//   0 Watchdog Time is set to 1.3s
+	// * Reserved         5
+	Bits uint8
+}
+
+type chipMode508Bits struct {
+	SelectorMode     bool `json:"selector_mode"`
+	TTLEnabled       bool `json:"ttl_enabled"`
+	WatchdogDuration bool `json:"watchdog_duration"`
+	Reserved         byte `json:"reserved"`
+}
+
+func (cm ChipMode508) SelectorMode() bool {
+	return cm.Bits&0x01 != 0
+}
+
+func (cm ChipMode508) TTLEnabled() bool {
+	return (cm.Bits & 0x02) != 0
+}
+
+func (cm ChipMode508) WatchdogDuration() bool {
+	return (cm.Bits & 0x04) != 0
+}
+
+func (cm ChipMode508) Reserved() byte {
+	return cm.Bits & 0xf8 >> 3
+}
+
+func (cm ChipMode508) WithSelectorMode(on bool) ChipMode508 {
+	cm.Bits = setFlag(cm.Bits, 0x01, on)
+	return cm
+}
+
+func (cm ChipMode508) WithTTLEnabled(on bool) ChipMode508 {
+	cm.Bits = setFlag(cm.Bits, 0x02, on)
+	return cm
+}
+
+func (cm ChipMode508) WithWatchdogDuration(long bool) ChipMode508 {
+	cm.Bits = setFlag(cm.Bits, 0x04, long)
+	return cm
+}
+
+func (cm ChipMode508) MarshalJSON() ([]byte, error) {
+	return json.Marshal(chipMode508Bits{
+		SelectorMode:     cm.SelectorMode(),
+		TTLEnabled:       cm.TTLEnabled(),
+		WatchdogDuration: cm.WatchdogDuration(),
+		Reserved:         cm.Reserved(),
+	})
+}
+
+func (cm *ChipMode508) UnmarshalJSON(data []byte) error {
+	var b chipMode508Bits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := checkBits("reserved", 0xf8, b.Reserved); err != nil {
+		return err
+	}
+	*cm = ChipMode508{Bits: setBits(0, 0xf8, b.Reserved)}.
+		WithSelectorMode(b.SelectorMode).
+		WithTTLEnabled(b.TTLEnabled).
+		WithWatchdogDuration(b.WatchdogDuration)
+	return nil
+}
+
 type SlotConfig struct {
 	// Bits1 consists of
 	// * ReadKey (4)
@@ -269,6 +543,44 @@ func (sc SlotConfig) WriteConfig() SlotWriteConfig {
 	}
 }
 
+func (sc SlotConfig) WithReadKey(key uint16) SlotConfig {
+	sc.Bits1 = setBits(sc.Bits1, 0x0f, key)
+	return sc
+}
+
+func (sc SlotConfig) WithNoMac(on bool) SlotConfig {
+	sc.Bits1 = setFlag(sc.Bits1, 0x10, on)
+	return sc
+}
+
+func (sc SlotConfig) WithLimitedUse(on bool) SlotConfig {
+	sc.Bits1 = setFlag(sc.Bits1, 0x20, on)
+	return sc
+}
+
+func (sc SlotConfig) WithEncryptRead(on bool) SlotConfig {
+	sc.Bits1 = setFlag(sc.Bits1, 0x40, on)
+	return sc
+}
+
+func (sc SlotConfig) WithIsSecret(on bool) SlotConfig {
+	sc.Bits1 = setFlag(sc.Bits1, 0x80, on)
+	return sc
+}
+
+func (sc SlotConfig) WithWriteKey(key uint16) SlotConfig {
+	sc.Bits2 = setBits(sc.Bits2, 0x0f, key)
+	return sc
+}
+
+func (sc SlotConfig) WithWriteConfig(wc SlotWriteConfig) SlotConfig {
+	conf := setBits(0, 0x0c, wc.Unknown2)
+	conf = setFlag(conf, 0x01, wc.Unknown)
+	conf = setFlag(conf, 0x02, wc.GenKeyEnabled)
+	sc.Bits2 = setBits(sc.Bits2, 0xf0, conf)
+	return sc
+}
+
 func (sc SlotConfig) MarshalJSON() ([]byte, error) {
 	return json.Marshal(slotConfigBits{
 		ReadKey:     sc.ReadKey(),
@@ -281,11 +593,40 @@ func (sc SlotConfig) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (sc *SlotConfig) UnmarshalJSON(data []byte) error {
+	var b slotConfigBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("read_key", 0x0f, b.ReadKey),
+		checkBits("write_key", 0x0f, b.WriteKey),
+		checkBits("write_config.unknown2", 0x03, b.WriteConfig.Unknown2),
+	); err != nil {
+		return err
+	}
+	*sc = SlotConfig{}.
+		WithReadKey(b.ReadKey).
+		WithNoMac(b.NoMAC).
+		WithLimitedUse(b.LimitedUse).
+		WithEncryptRead(b.EncryptRead).
+		WithIsSecret(b.IsSecret).
+		WithWriteKey(b.WriteKey).
+		WithWriteConfig(b.WriteConfig)
+	return nil
+}
+
 // Counter is a monotonic counter.
 type Counter struct {
 	Value [8]uint8 `json:"value"`
 }
 
+// UseFlag limits the number of uses of a key on ATSHA204 devices.
+type UseFlag struct {
+	UseFlag     byte `json:"use_flag"`
+	UpdateCount byte `json:"update_count"`
+}
+
 type UseLock struct {
 	// Bits consists of
 	// * UseLockEnable (4)
@@ -306,6 +647,16 @@ func (ul UseLock) UseLockKey() byte {
 	return ul.Bits & 0xf0 >> 4
 }
 
+func (ul UseLock) WithUseLockEnable(v byte) UseLock {
+	ul.Bits = setBits(ul.Bits, 0x0f, v)
+	return ul
+}
+
+func (ul UseLock) WithUseLockKey(key byte) UseLock {
+	ul.Bits = setBits(ul.Bits, 0xf0, key)
+	return ul
+}
+
 func (ul UseLock) MarshalJSON() ([]byte, error) {
 	return json.Marshal(useLockBits{
 		UseLockEnable: ul.UseLockEnable(),
@@ -313,6 +664,23 @@ func (ul UseLock) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (ul *UseLock) UnmarshalJSON(data []byte) error {
+	var b useLockBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("use_lock_enable", 0x0f, b.UseLockEnable),
+		checkBits("use_lock_key", 0xf0, b.UseLockKey),
+	); err != nil {
+		return err
+	}
+	*ul = UseLock{}.
+		WithUseLockEnable(b.UseLockEnable).
+		WithUseLockKey(b.UseLockKey)
+	return nil
+}
+
 type VolatileKeyPermission struct {
 	// Bits consists of:
 	// * VolatileKeyPermitSlot (4)
@@ -339,6 +707,16 @@ func (vkp VolatileKeyPermission) Enabled() bool {
 	return vkp.Bits&0x80 != 0
 }
 
+func (vkp VolatileKeyPermission) WithSlot(slot byte) VolatileKeyPermission {
+	vkp.Bits = setBits(vkp.Bits, 0x0f, slot)
+	return vkp
+}
+
+func (vkp VolatileKeyPermission) WithEnabled(on bool) VolatileKeyPermission {
+	vkp.Bits = setFlag(vkp.Bits, 0x80, on)
+	return vkp
+}
+
 func (vkp VolatileKeyPermission) MarshalJSON() ([]byte, error) {
 	return json.Marshal(VolatileKeyPermissionBits{
 		Slot:     vkp.Slot(),
@@ -347,6 +725,23 @@ func (vkp VolatileKeyPermission) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (vkp *VolatileKeyPermission) UnmarshalJSON(data []byte) error {
+	var b VolatileKeyPermissionBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("slot", 0x0f, b.Slot),
+		checkBits("reserved", 0x70, b.Reserved),
+	); err != nil {
+		return err
+	}
+	*vkp = VolatileKeyPermission{Bits: setBits(0, 0x70, b.Reserved)}.
+		WithSlot(b.Slot).
+		WithEnabled(b.Enabled)
+	return nil
+}
+
 type SecureBoot struct {
 	// Bits1 consists of
 	// * SecureBootMode             2
@@ -391,8 +786,10 @@ func (sb SecureBoot) PersistentEnabled() bool {
 func (sb SecureBoot) RandNonce() bool {
 	return sb.Bits1&0x10 != 0
 }
+
+// Reserved1 returns bits 5 to 7 of Bits1, shifted down.
 func (sb SecureBoot) Reserved1() uint8 {
-	return sb.Bits1 & 0xe0 >> 4
+	return sb.Bits1 & 0xe0 >> 5
 }
 func (sb SecureBoot) SigDig() byte {
 	return sb.Bits2 & 0x0f
@@ -402,6 +799,31 @@ func (sb SecureBoot) PublicKey() byte {
 	return sb.Bits2 & 0xf0 >> 4
 }
 
+func (sb SecureBoot) WithMode(mode uint8) SecureBoot {
+	sb.Bits1 = setBits(sb.Bits1, 0x03, mode)
+	return sb
+}
+
+func (sb SecureBoot) WithPersistentEnabled(on bool) SecureBoot {
+	sb.Bits1 = setFlag(sb.Bits1, 0x08, on)
+	return sb
+}
+
+func (sb SecureBoot) WithRandNonce(on bool) SecureBoot {
+	sb.Bits1 = setFlag(sb.Bits1, 0x10, on)
+	return sb
+}
+
+func (sb SecureBoot) WithSigDig(slot byte) SecureBoot {
+	sb.Bits2 = setBits(sb.Bits2, 0x0f, slot)
+	return sb
+}
+
+func (sb SecureBoot) WithPublicKey(slot byte) SecureBoot {
+	sb.Bits2 = setBits(sb.Bits2, 0xf0, slot)
+	return sb
+}
+
 func (sb SecureBoot) MarshalJSON() ([]byte, error) {
 	return json.Marshal(secureBootBits{
 		Mode:              sb.Mode(),
@@ -414,6 +836,31 @@ func (sb SecureBoot) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (sb *SecureBoot) UnmarshalJSON(data []byte) error {
+	var b secureBootBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("mode", 0x03, b.Mode),
+		checkBits("reserved0", 0x04, b.Reserved0),
+		checkBits("reserved1", 0xe0, b.Reserved1),
+		checkBits("sig_dig", 0x0f, b.SigDig),
+		checkBits("public_key", 0xf0, b.PublicKey),
+	); err != nil {
+		return err
+	}
+	reserved := setBits(0, 0x04, b.Reserved0)
+	reserved = setBits(reserved, 0xe0, b.Reserved1)
+	*sb = SecureBoot{Bits1: reserved}.
+		WithMode(b.Mode).
+		WithPersistentEnabled(b.PersistentEnabled).
+		WithRandNonce(b.RandNonce).
+		WithSigDig(b.SigDig).
+		WithPublicKey(b.PublicKey)
+	return nil
+}
+
 type LockState byte
 
 const (
@@ -423,6 +870,8 @@ const (
 	LockStateUnlocked = LockState(0x55)
 )
 
+var lockStates = []LockState{LockStateLocked, LockStateUnlocked}
+
 func (m LockState) IsLocked() bool {
 	return m != LockStateUnlocked
 }
@@ -439,16 +888,29 @@ func (m LockState) String() string {
 }
 
 func (m LockState) MarshalJSON() ([]byte, error) {
-	return json.Marshal(m.String())
+	return marshalEnum(m, lockStates)
 }
 
+func (m *LockState) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, m, lockStates)
+}
+
+// SlotLocked holds a bit per slot, cleared when the slot is locked.
+//
+// The field is decoded big endian like the rest of the configuration, while
+// the first byte holds slots 0 to 7 and the second byte slots 8 to 15.
 type SlotLocked uint16
 
+// slotLockedBit returns the bit of the slot.
+func slotLockedBit(slot int) SlotLocked {
+	return 1 << ((slot + 8) % 16)
+}
+
 func (l SlotLocked) IsLocked(slot int) bool {
 	if slot >= 16 {
 		panic("slot locked contains only 16 slots")
 	}
-	return int(l)&(1<<slot) == 0
+	return l&slotLockedBit(slot) == 0
 }
 
 func (l SlotLocked) MarshalJSON() ([]byte, error) {
@@ -459,6 +921,25 @@ func (l SlotLocked) MarshalJSON() ([]byte, error) {
 	return json.Marshal(slots)
 }
 
+func (l *SlotLocked) UnmarshalJSON(data []byte) error {
+	var slots []bool
+	if err := json.Unmarshal(data, &slots); err != nil {
+		return err
+	}
+	if len(slots) != 16 {
+		return fmt.Errorf("atecc: slot locked must contain 16 slots, got %d", len(slots))
+	}
+
+	var v SlotLocked
+	for i, locked := range slots {
+		if !locked {
+			v |= slotLockedBit(i)
+		}
+	}
+	*l = v
+	return nil
+}
+
 type ChipOptions struct {
 	// Bits1 consists of
 	// * PowerOnSelfTest       1
@@ -533,6 +1014,41 @@ func (co ChipOptions) IoProtectionKey() byte {
 	return co.Bits2 & 0xf0 >> 4
 }
 
+func (co ChipOptions) WithPowerOnSelfTest(on bool) ChipOptions {
+	co.Bits1 = setFlag(co.Bits1, 0x01, on)
+	return co
+}
+
+func (co ChipOptions) WithIoProtectionKeyEnabled(on bool) ChipOptions {
+	co.Bits1 = setFlag(co.Bits1, 0x02, on)
+	return co
+}
+
+func (co ChipOptions) WithKdfAesEnabled(on bool) ChipOptions {
+	co.Bits1 = setFlag(co.Bits1, 0x04, on)
+	return co
+}
+
+func (co ChipOptions) WithAutoClearFirstFail(on bool) ChipOptions {
+	co.Bits1 = setFlag(co.Bits1, 0x08, on)
+	return co
+}
+
+func (co ChipOptions) WithEcdhProtectionBits(v byte) ChipOptions {
+	co.Bits2 = setBits(co.Bits2, 0x03, v)
+	return co
+}
+
+func (co ChipOptions) WithKdfProtectionBits(v byte) ChipOptions {
+	co.Bits2 = setBits(co.Bits2, 0x0c, v)
+	return co
+}
+
+func (co ChipOptions) WithIoProtectionKey(slot byte) ChipOptions {
+	co.Bits2 = setBits(co.Bits2, 0xf0, slot)
+	return co
+}
+
 func (co ChipOptions) MarshalJSON() ([]byte, error) {
 	return json.Marshal(chipOptionsBits{
 		PowerOnSelfTest:        co.PowerOnSelfTest(),
@@ -546,6 +1062,30 @@ func (co ChipOptions) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (co *ChipOptions) UnmarshalJSON(data []byte) error {
+	var b chipOptionsBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("reserved", 0xf0, b.Reserved),
+		checkBits("ecdh_protection_bits", 0x03, b.EcdhProtectionBits),
+		checkBits("kdf_protection_bits", 0x0c, b.KdfProtectionBits),
+		checkBits("io_protection_key", 0xf0, b.IoProtectionKey),
+	); err != nil {
+		return err
+	}
+	*co = ChipOptions{Bits1: setBits(0, 0xf0, b.Reserved)}.
+		WithPowerOnSelfTest(b.PowerOnSelfTest).
+		WithIoProtectionKeyEnabled(b.IoProtectionKeyEnabled).
+		WithKdfAesEnabled(b.KdfAesEnable).
+		WithAutoClearFirstFail(b.AutoClearFirstFail).
+		WithEcdhProtectionBits(b.EcdhProtectionBits).
+		WithKdfProtectionBits(b.KdfProtectionBits).
+		WithIoProtectionKey(b.IoProtectionKey)
+	return nil
+}
+
 type X509Format struct {
 	// Bits consists of
 	// * PublicPosition 4
@@ -566,6 +1106,16 @@ func (xf X509Format) TemplateLength() byte {
 	return xf.Bits & 0xf0 >> 4
 }
 
+func (xf X509Format) WithPublicPosition(v byte) X509Format {
+	xf.Bits = setBits(xf.Bits, 0x0f, v)
+	return xf
+}
+
+func (xf X509Format) WithTemplateLength(v byte) X509Format {
+	xf.Bits = setBits(xf.Bits, 0xf0, v)
+	return xf
+}
+
 func (xf X509Format) MarshalJSON() ([]byte, error) {
 	return json.Marshal(x509FormatBits{
 		PublicPosition: xf.PublicPosition(),
@@ -573,6 +1123,23 @@ func (xf X509Format) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (xf *X509Format) UnmarshalJSON(data []byte) error {
+	var b x509FormatBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("public_position", 0x0f, b.PublicPosition),
+		checkBits("template_length", 0xf0, b.TemplateLength),
+	); err != nil {
+		return err
+	}
+	*xf = X509Format{}.
+		WithPublicPosition(b.PublicPosition).
+		WithTemplateLength(b.TemplateLength)
+	return nil
+}
+
 type KeyType uint8
 
 const (
@@ -591,6 +1158,8 @@ const (
 	KeyTypeOther = KeyType(0x07)
 )
 
+var keyTypes = []KeyType{KeyTypePrivate, KeyTypeAES, KeyTypeOther}
+
 func (k KeyType) String() string {
 	switch k {
 	case KeyTypePrivate:
@@ -605,7 +1174,11 @@ func (k KeyType) String() string {
 }
 
 func (k KeyType) MarshalJSON() ([]byte, error) {
-	return json.Marshal(k.String())
+	return marshalEnum(k, keyTypes)
+}
+
+func (k *KeyType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, k, keyTypes)
 }
 
 type KeyConfig struct {
@@ -678,6 +1251,51 @@ func (kc KeyConfig) X509ID() byte {
 	return kc.Bits2 & 0xc0 >> 6
 }
 
+func (kc KeyConfig) WithPrivate(on bool) KeyConfig {
+	kc.Bits1 = setFlag(kc.Bits1, 0x01, on)
+	return kc
+}
+
+func (kc KeyConfig) WithPubInfo(on bool) KeyConfig {
+	kc.Bits1 = setFlag(kc.Bits1, 0x02, on)
+	return kc
+}
+
+func (kc KeyConfig) WithKeyType(kt KeyType) KeyConfig {
+	kc.Bits1 = setBits(kc.Bits1, 0x1c, kt)
+	return kc
+}
+
+func (kc KeyConfig) WithLockable(on bool) KeyConfig {
+	kc.Bits1 = setFlag(kc.Bits1, 0x20, on)
+	return kc
+}
+
+func (kc KeyConfig) WithRequireRandom(on bool) KeyConfig {
+	kc.Bits1 = setFlag(kc.Bits1, 0x40, on)
+	return kc
+}
+
+func (kc KeyConfig) WithRequireAuth(on bool) KeyConfig {
+	kc.Bits1 = setFlag(kc.Bits1, 0x80, on)
+	return kc
+}
+
+func (kc KeyConfig) WithAuthKey(slot byte) KeyConfig {
+	kc.Bits2 = setBits(kc.Bits2, 0x0f, slot)
+	return kc
+}
+
+func (kc KeyConfig) WithPersistentDisable(on bool) KeyConfig {
+	kc.Bits2 = setFlag(kc.Bits2, 0x10, on)
+	return kc
+}
+
+func (kc KeyConfig) WithX509ID(id byte) KeyConfig {
+	kc.Bits2 = setBits(kc.Bits2, 0xc0, id)
+	return kc
+}
+
 func (kc KeyConfig) MarshalJSON() ([]byte, error) {
 	return json.Marshal(keyConfigBits{
 		Private:           kc.Private(),
@@ -693,6 +1311,31 @@ func (kc KeyConfig) MarshalJSON() ([]byte, error) {
 	})
 }
 
+func (kc *KeyConfig) UnmarshalJSON(data []byte) error {
+	var b keyConfigBits
+	if err := unmarshalBits(data, &b); err != nil {
+		return err
+	}
+	if err := errors.Join(
+		checkBits("key_type", 0x1c, b.KeyType),
+		checkBits("auth_key", 0x0f, b.AuthKey),
+		checkBits("x509_id", 0xc0, b.X509ID),
+	); err != nil {
+		return err
+	}
+	*kc = KeyConfig{Bits2: setFlag(0, 0x20, b.RFU)}.
+		WithPrivate(b.Private).
+		WithPubInfo(b.PubInfo).
+		WithKeyType(b.KeyType).
+		WithLockable(b.Lockable).
+		WithRequireRandom(b.RequireRandom).
+		WithRequireAuth(b.RequireAuth).
+		WithAuthKey(b.AuthKey).
+		WithPersistentDisable(b.PersistentDisable).
+		WithX509ID(b.X509ID)
+	return nil
+}
+
 // Config608 represents the configuration used in ATECC608 devices.
 type Config608 struct {
 	SN03                  [4]byte               `json:"sn03"`
@@ -727,6 +1370,86 @@ type Config608 struct {
 	KeyConfig   [16]KeyConfig `json:"key_config"`
 }
 
+// Config508 represents the configuration used in ATECC508 devices.
+type Config508 struct {
+	SN03       [4]byte        `json:"sn03"`
+	RevNum     [4]byte        `json:"revision"`
+	SN48       [5]byte        `json:"sn48"`
+	Reserved13 byte           `json:"reserved13"`
+	I2CEnable  I2CEnable      `json:"i2c_enable"`
+	Reserved15 byte           `json:"reserved15"`
+	I2CAddress byte           `json:"i2c_address"`
+	Reserved17 byte           `json:"reserved17"`
+	OTPMode    OTPMode        `json:"otp_mode"`
+	ChipMode   ChipMode508    `json:"chip_mode"`
+	SlotConfig [16]SlotConfig `json:"slot_config"`
+	Counter    [2]Counter     `json:"counter"`
+	LastKeyUse [16]byte       `json:"last_key_use"`
+	UserExtra  byte           `json:"user_extra"`
+	Selector   byte           `json:"selector"`
+
+	// LockValue indicates if the data zone has been locked.
+	LockValue LockState `json:"lock_value"`
+	// LockConfig indicates if the config zone has been locked.
+	LockConfig LockState `json:"lock_config"`
+
+	SlotLocked SlotLocked    `json:"slot_locked"`
+	RFU        [2]byte       `json:"rfu"`
+	X509Format [4]X509Format `json:"x509_format"`
+	KeyConfig  [16]KeyConfig `json:"key_config"`
+}
+
+// Config204 represents the configuration used in ATSHA204 devices.
+type Config204 struct {
+	SN03           [4]byte        `json:"sn03"`
+	RevNum         [4]byte        `json:"revision"`
+	SN48           [5]byte        `json:"sn48"`
+	Reserved13     byte           `json:"reserved13"`
+	I2CEnable      I2CEnable      `json:"i2c_enable"`
+	Reserved15     byte           `json:"reserved15"`
+	I2CAddress     byte           `json:"i2c_address"`
+	CheckMacConfig byte           `json:"check_mac_config"`
+	OTPMode        OTPMode        `json:"otp_mode"`
+	SelectorMode   byte           `json:"selector_mode"`
+	SlotConfig     [16]SlotConfig `json:"slot_config"`
+	UseFlag        [8]UseFlag     `json:"use_flag"`
+	LastKeyUse     [16]byte       `json:"last_key_use"`
+	UserExtra      byte           `json:"user_extra"`
+	Selector       byte           `json:"selector"`
+
+	// LockValue indicates if the data zone has been locked.
+	LockValue LockState `json:"lock_value"`
+	// LockConfig indicates if the config zone has been locked.
+	LockConfig LockState `json:"lock_config"`
+}
+
+// ConfigCA2 represents the configuration used in ECC204, SHA104, SHA105 and
+// TA010 devices.
+//
+// The configuration zone is divided into four subzones of 16 bytes each,
+// which are read, written and locked individually. The first subzone is
+// programmed and locked by the factory.
+type ConfigCA2 struct {
+	// Subzone 0
+	SN03       [4]byte `json:"sn03"`
+	RevNum     [4]byte `json:"revision"`
+	SN48       [5]byte `json:"sn48"`
+	Reserved13 [3]byte `json:"reserved13"`
+
+	// Subzone 1
+	ChipMode   byte       `json:"chip_mode"`
+	I2CAddress byte       `json:"i2c_address"`
+	Reserved18 [2]byte    `json:"reserved18"`
+	SlotConfig [4][2]byte `json:"slot_config"`
+	Reserved28 [4]byte    `json:"reserved28"`
+
+	// Subzone 2
+	Counter [16]byte `json:"counter"`
+
+	// Subzone 3
+	UserData [16]byte `json:"user_data"`
+}
+
 func Marshal(v any) ([]byte, error) {
 	var buf bytes.Buffer
 	err := binary.Write(&buf, binary.BigEndian, v)
@@ -743,6 +1466,12 @@ func UnmarshalPartial(config []byte, offset int, data any) error {
 	switch data.(type) {
 	case *Config608:
 		size = PermanentOffset608 + len(Default608)
+	case *Config508:
+		size = ConfigSize508
+	case *Config204:
+		size = ConfigSize204
+	case *ConfigCA2:
+		size = ConfigSizeCA2
 	default:
 		return errors.New("atecc: unsupported config")
 	}
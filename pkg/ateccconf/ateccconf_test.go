@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"testing/quick"
 )
 
 var (
@@ -214,3 +215,272 @@ func TestUnmarshalPartial(t *testing.T) {
 		t.Errorf("want: %v", want)
 	}
 }
+
+func TestConfigSize(t *testing.T) {
+	testCases := []struct {
+		name string
+		conf any
+		size int
+	}{
+		{"608", &Config608{}, PermanentOffset608 + len(Default608)},
+		{"508", &Config508{}, ConfigSize508},
+		{"204", &Config204{}, ConfigSize204},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := Marshal(tc.conf)
+			if err != nil {
+				t.Fatal(err)
+			} else if len(b) != tc.size {
+				t.Errorf("got %d bytes, want %d", len(b), tc.size)
+			}
+
+			// the lock bytes share the same offset on all devices
+			lock := []byte{0x01, 0x02, byte(LockStateLocked), byte(LockStateUnlocked)}
+			if err := UnmarshalPartial(lock, LockOffset, tc.conf); err != nil {
+				t.Fatal(err)
+			}
+			b, _ = Marshal(tc.conf)
+			if !bytes.Equal(b[LockOffset:LockOffset+4], lock) {
+				t.Errorf("got %x, want %x", b[LockOffset:LockOffset+4], lock)
+			}
+		})
+	}
+}
+
+func TestSecureBootReserved1(t *testing.T) {
+	for _, tc := range []struct {
+		bits1 byte
+		want  uint8
+	}{
+		{0x10, 0}, // rand nonce is not part of the field
+		{0x20, 1},
+		{0xe0, 7},
+		{0xff, 7},
+	} {
+		sb := SecureBoot{Bits1: tc.bits1}
+		if got := sb.Reserved1(); got != tc.want {
+			t.Errorf("%#02x: got %d, want %d", tc.bits1, got, tc.want)
+		}
+		data, err := json.Marshal(sb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got SecureBoot
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != sb {
+			t.Errorf("%#02x: got %+v after json round trip", tc.bits1, got)
+		}
+	}
+}
+
+func TestSlotLocked(t *testing.T) {
+	// bytes 88 and 89 as read from a device with slots 0 and 15 locked
+	config := append([]byte(nil), golden608...)
+	config[88] = 0xfe // slot 0
+	config[89] = 0x7f // slot 15
+
+	var c Config608
+	if err := Unmarshal(config, &c); err != nil {
+		t.Fatal(err)
+	}
+	for slot := 0; slot < 16; slot++ {
+		want := slot == 0 || slot == 15
+		if got := c.SlotLocked.IsLocked(slot); got != want {
+			t.Errorf("slot %d: got locked %v, want %v", slot, got, want)
+		}
+	}
+
+	data, err := json.Marshal(c.SlotLocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l SlotLocked
+	if err := json.Unmarshal(data, &l); err != nil {
+		t.Fatal(err)
+	}
+	if l != c.SlotLocked {
+		t.Errorf("got %#04x after json round trip, want %#04x", l, c.SlotLocked)
+	}
+	got, err := Marshal(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[88] != 0xfe || got[89] != 0x7f {
+		t.Errorf("got slot locked bytes %x, want fe7f", got[88:90])
+	}
+}
+
+func TestSetters(t *testing.T) {
+	sc := SlotConfig{}.WithReadKey(5).WithIsSecret(true)
+	if want := (SlotConfig{Bits1: 0x85, Bits2: 0x00}); sc != want {
+		t.Errorf("got %+v, want %+v", sc, want)
+	}
+
+	// rebuilding every field from its getters must give the same bits
+	c := DefaultConfig608()
+	for i, want := range c.SlotConfig {
+		got := SlotConfig{}.
+			WithReadKey(want.ReadKey()).
+			WithNoMac(want.NoMac()).
+			WithLimitedUse(want.LimitedUse()).
+			WithEncryptRead(want.EncryptRead()).
+			WithIsSecret(want.IsSecret()).
+			WithWriteKey(want.WriteKey()).
+			WithWriteConfig(want.WriteConfig())
+		if got != want {
+			t.Errorf("slot config %d: got %+v, want %+v", i, got, want)
+		}
+	}
+	for i, want := range c.KeyConfig {
+		got := KeyConfig{}.
+			WithPrivate(want.Private()).
+			WithPubInfo(want.PubInfo()).
+			WithKeyType(want.KeyType()).
+			WithLockable(want.Lockable()).
+			WithRequireRandom(want.RequireRandom()).
+			WithRequireAuth(want.RequireAuth()).
+			WithAuthKey(want.AuthKey()).
+			WithPersistentDisable(want.PersistentDisable()).
+			WithX509ID(want.X509ID())
+		if got != want {
+			t.Errorf("key config %d: got %+v, want %+v", i, got, want)
+		}
+	}
+
+	cm := ChipMode608{}.
+		WithUserExtraAdd(c.ChipMode.UserExtraAdd()).
+		WithTTLEnabled(c.ChipMode.TTLEnabled()).
+		WithWatchdogDuration(c.ChipMode.WatchdogDuration()).
+		WithClockDivider(c.ChipMode.ClockDivider())
+	if cm != c.ChipMode {
+		t.Errorf("chip mode: got %+v, want %+v", cm, c.ChipMode)
+	}
+
+	sb := SecureBoot{}.
+		WithMode(c.SecureBoot.Mode()).
+		WithPersistentEnabled(c.SecureBoot.PersistentEnabled()).
+		WithRandNonce(c.SecureBoot.RandNonce()).
+		WithSigDig(c.SecureBoot.SigDig()).
+		WithPublicKey(c.SecureBoot.PublicKey())
+	if sb != c.SecureBoot {
+		t.Errorf("secure boot: got %+v, want %+v", sb, c.SecureBoot)
+	}
+
+	co := ChipOptions{}.
+		WithPowerOnSelfTest(c.ChipOptions.PowerOnSelfTest()).
+		WithIoProtectionKeyEnabled(c.ChipOptions.IoProtectionKeyEnabled()).
+		WithKdfAesEnabled(c.ChipOptions.KdfAesEnabled()).
+		WithAutoClearFirstFail(c.ChipOptions.AutoClearFirstFail()).
+		WithEcdhProtectionBits(c.ChipOptions.EcdhProtectionBits()).
+		WithKdfProtectionBits(c.ChipOptions.KdfProtectionBits()).
+		WithIoProtectionKey(c.ChipOptions.IoProtectionKey())
+	if co != c.ChipOptions {
+		t.Errorf("chip options: got %+v, want %+v", co, c.ChipOptions)
+	}
+
+	// setters only touch their own field
+	sc = SlotConfig{Bits1: 0xff, Bits2: 0xff}.WithReadKey(0).WithWriteConfig(SlotWriteConfig{})
+	if want := (SlotConfig{Bits1: 0xf0, Bits2: 0x0f}); sc != want {
+		t.Errorf("got %+v, want %+v", sc, want)
+	}
+
+	// values which do not fit in their field are never truncated
+	for name, set := range map[string]func(){
+		"read key":     func() { SlotConfig{}.WithReadKey(16) },
+		"write key":    func() { SlotConfig{}.WithWriteKey(256) },
+		"write config": func() { SlotConfig{}.WithWriteConfig(SlotWriteConfig{Unknown2: 4}) },
+		"key type":     func() { KeyConfig{}.WithKeyType(8) },
+		"auth key":     func() { KeyConfig{}.WithAuthKey(16) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			set()
+		}()
+	}
+}
+
+func TestJSONRoundtrip(t *testing.T) {
+	// roundtrip reports whether the config in b survives a JSON roundtrip.
+	roundtrip := func(b []byte, newConf func() any) bool {
+		conf := newConf()
+		if err := Unmarshal(b, conf); err != nil {
+			t.Fatal(err)
+		}
+		j, err := json.Marshal(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := newConf()
+		if err := json.Unmarshal(j, got); err != nil {
+			t.Errorf("%s: %v", j, err)
+			return false
+		}
+		m, err := Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes.Equal(m, b)
+	}
+
+	testCases := []struct {
+		name string
+		fn   any
+	}{
+		{"608", func(b [128]byte) bool {
+			return roundtrip(b[:], func() any { return &Config608{} })
+		}},
+		{"508", func(b [ConfigSize508]byte) bool {
+			return roundtrip(b[:], func() any { return &Config508{} })
+		}},
+		{"204", func(b [ConfigSize204]byte) bool {
+			return roundtrip(b[:], func() any { return &Config204{} })
+		}},
+		{"CA2", func(b [ConfigSizeCA2]byte) bool {
+			return roundtrip(b[:], func() any { return &ConfigCA2{} })
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := quick.Check(tc.fn, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		v    any
+		in   string
+	}{
+		{"range", &SlotConfig{}, `{"read_key": 16}`},
+		{"reserved", &SecureBoot{}, `{"reserved1": 8}`},
+		{"unknown field", &KeyConfig{}, `{"key_typ": "aes"}`},
+		{"unknown name", new(ClockDivider), `"m3"`},
+		{"enum range", &KeyConfig{}, `{"key_type": 8}`},
+		{"slots", new(SlotLocked), `[true, false]`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tc.in), tc.v); err == nil {
+				t.Errorf("expected error for %s", tc.in)
+			}
+		})
+	}
+
+	// unnamed values are kept as numbers
+	var kc KeyConfig
+	if err := json.Unmarshal([]byte(`{"key_type": 5}`), &kc); err != nil {
+		t.Fatal(err)
+	} else if kc.KeyType() != 5 {
+		t.Errorf("got key type %d, want 5", kc.KeyType())
+	}
+}
@@ -0,0 +1,24 @@
+package ateccconf
+
+// LockCRC returns the CRC-16 verified by the device when locking a zone or
+// slot with the contents.
+//
+// For the configuration zone, the contents is the full zone including the
+// first 16 bytes and the lock bytes. For the data zone, it is all the slots in
+// order followed by the OTP zone. For a single slot, it is the slot contents.
+func LockCRC(contents []byte) uint16 {
+	const polynom uint16 = 0x8005
+	var crc uint16
+
+	for _, b := range contents {
+		for j := 0; j < 8; j++ {
+			dataBit := b >> j & 0x01
+			crcBit := byte(crc >> 15)
+			crc <<= 1
+			if dataBit != crcBit {
+				crc ^= polynom
+			}
+		}
+	}
+	return crc
+}
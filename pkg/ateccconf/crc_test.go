@@ -0,0 +1,21 @@
+package ateccconf
+
+import "testing"
+
+func TestLockCRC(t *testing.T) {
+	testCases := []struct {
+		crc uint16
+		in  []byte
+	}{
+		{0x0000, nil},
+		{0x0000, make([]byte, 72)},
+		{0x8317, []byte("a")},
+		{0x1ce9, []byte("abc")},
+		{0xae0f, []byte("abcdefghi")},
+	}
+	for _, tc := range testCases {
+		if crc := LockCRC(tc.in); crc != tc.crc {
+			t.Errorf("LockCRC(%x): got %#x want %#x", tc.in, crc, tc.crc)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package ateccconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Difference is a field which differs between two configurations.
+type Difference struct {
+	// Field is the JSON path of the field, e.g. slot_config[5].write_key.
+	Field string `json:"field"`
+	// A and B are the JSON encoded values of the field.
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Field, d.A, d.B)
+}
+
+// DiffOptions controls which fields are compared by Diff.
+type DiffOptions struct {
+	// IgnoreFactory ignores the first 16 bytes, which are programmed by the
+	// factory and unique to each device.
+	IgnoreFactory bool
+	// IgnoreLock ignores the lock bytes, including the slot locks.
+	IgnoreLock bool
+}
+
+var (
+	factoryFields = []string{"sn03", "revision", "sn48", "aes_enable", "i2c_enable", "reserved15"}
+	lockFields    = []string{"lock_value", "lock_config", "slot_locked"}
+)
+
+// Diff returns the fields which differ between the configurations.
+//
+// The fields are compared in their JSON form, which means bitfields are
+// compared bit by bit and reported by name. The differences are ordered as
+// the fields appear in the configuration.
+func Diff(a, b *Config608, opts DiffOptions) ([]Difference, error) {
+	var ignore []string
+	if opts.IgnoreFactory {
+		ignore = append(ignore, factoryFields...)
+	}
+	if opts.IgnoreLock {
+		ignore = append(ignore, lockFields...)
+	}
+
+	fa, err := flattenJSON(a)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := flattenJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(fb))
+	for _, f := range fb {
+		values[f.path] = f.value
+	}
+
+	var diffs []Difference
+	for _, f := range fa {
+		if isIgnored(f.path, ignore) {
+			continue
+		}
+		if v := values[f.path]; v != f.value {
+			diffs = append(diffs, Difference{Field: f.path, A: f.value, B: v})
+		}
+	}
+	return diffs, nil
+}
+
+// isIgnored returns true if the path is within one of the top-level fields.
+func isIgnored(path string, fields []string) bool {
+	name := path
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		name = path[:i]
+	}
+	for _, f := range fields {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+type flatField struct {
+	path  string
+	value string
+}
+
+// flattenJSON returns the leaf values of the JSON form of v, in order.
+func flattenJSON(v any) ([]flatField, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields []flatField
+	if err := flatten(dec, "", &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func flatten(dec *json.Decoder, path string, fields *[]flatField) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			p := key.(string)
+			if path != "" {
+				p = path + "." + p
+			}
+			if err := flatten(dec, p, fields); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := flatten(dec, fmt.Sprintf("%s[%d]", path, i), fields); err != nil {
+				return err
+			}
+		}
+	default:
+		value, err := json.Marshal(tok)
+		if err != nil {
+			return err
+		}
+		*fields = append(*fields, flatField{path: path, value: string(value)})
+		return nil
+	}
+
+	// consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
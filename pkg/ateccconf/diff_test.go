@@ -0,0 +1,53 @@
+package ateccconf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := DefaultConfig608()
+	b := DefaultConfig608()
+	b.SN03 = [4]byte{0x01, 0x23, 0x00, 0x00}
+	b.SlotConfig[5] = b.SlotConfig[5].WithWriteKey(3)
+	b.ChipMode = b.ChipMode.WithClockDivider(ClockDividerM1)
+	b.LockConfig = LockStateLocked
+
+	testCases := []struct {
+		name string
+		opts DiffOptions
+		want []string
+	}{
+		{"all", DiffOptions{}, []string{
+			"sn03[0]: 0 -> 1",
+			"sn03[1]: 0 -> 35",
+			`chip_mode.clock_divider: "m0" -> "m1"`,
+			"slot_config[5].write_key: 6 -> 3",
+			`lock_config: "unlocked" -> "locked"`,
+		}},
+		{"ignore", DiffOptions{IgnoreFactory: true, IgnoreLock: true}, []string{
+			`chip_mode.clock_divider: "m0" -> "m1"`,
+			"slot_config[5].write_key: 6 -> 3",
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diffs, err := Diff(a, b, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range diffs {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf(" got: %q", got)
+				t.Errorf("want: %q", tc.want)
+			}
+		})
+	}
+
+	if diffs, err := Diff(a, a, DiffOptions{}); err != nil || len(diffs) != 0 {
+		t.Errorf("got %v, %v, want no differences", diffs, err)
+	}
+}
@@ -0,0 +1,223 @@
+package ateccconf
+
+import (
+	"fmt"
+)
+
+// AccessMode is how data in a slot can be accessed.
+type AccessMode string
+
+const (
+	// AccessNever prohibits the access.
+	AccessNever = AccessMode("never")
+	// AccessClear permits the access in the clear.
+	AccessClear = AccessMode("clear")
+	// AccessEncrypted requires the data to be encrypted using a key.
+	AccessEncrypted = AccessMode("encrypted")
+	// AccessPubInvalid permits writes in the clear while the public key is
+	// invalidated.
+	AccessPubInvalid = AccessMode("pub-invalid")
+	// AccessSlot stores the data in another slot.
+	AccessSlot = AccessMode("slot")
+)
+
+// Access describes a way of reading or writing a slot.
+type Access struct {
+	Mode AccessMode `json:"mode"`
+	// Key is the slot of the encryption key, or of the slot the data is
+	// stored in, if the mode requires one.
+	Key *int `json:"key,omitempty"`
+}
+
+func newAccess(mode AccessMode, key byte) Access {
+	k := int(key)
+	return Access{Mode: mode, Key: &k}
+}
+
+func (a Access) String() string {
+	switch {
+	case a.Key == nil:
+		return string(a.Mode)
+	case a.Mode == AccessSlot:
+		return fmt.Sprintf("slot %d", *a.Key)
+	default:
+		return fmt.Sprintf("%s (key %d)", a.Mode, *a.Key)
+	}
+}
+
+// DeriveKeyAccess describes if the slot can be the target of DeriveKey.
+type DeriveKeyAccess struct {
+	Allowed bool `json:"allowed"`
+	// Parent is the slot of the parent key when creating a key. Rolling a
+	// key derives it from the current value of the slot.
+	Parent *int `json:"parent,omitempty"`
+	// RequireMAC indicates that an authorizing MAC is required.
+	RequireMAC bool `json:"require_mac"`
+}
+
+func (dk DeriveKeyAccess) String() string {
+	if !dk.Allowed {
+		return string(AccessNever)
+	}
+	s := "roll"
+	if dk.Parent != nil {
+		s = fmt.Sprintf("create (parent %d)", *dk.Parent)
+	}
+	if dk.RequireMAC {
+		s += ", mac"
+	}
+	return s
+}
+
+// SignAccess describes which messages a private key can sign.
+type SignAccess struct {
+	// External permits signing arbitrary external messages.
+	External bool `json:"external"`
+	// Internal permits signing messages generated by GenDig or GenKey.
+	Internal bool `json:"internal"`
+}
+
+func (sa SignAccess) String() string {
+	switch {
+	case sa.External && sa.Internal:
+		return "external, internal"
+	case sa.External:
+		return "external"
+	case sa.Internal:
+		return "internal"
+	default:
+		return string(AccessNever)
+	}
+}
+
+// SlotExplanation describes the permissions of a slot.
+//
+// It combines the SlotConfig and KeyConfig of the slot, together with the
+// chip options affecting it. The permissions are those in effect once the data
+// zone has been locked.
+type SlotExplanation struct {
+	Slot    int     `json:"slot"`
+	KeyType KeyType `json:"key_type"`
+	Private bool    `json:"private"`
+
+	Read      Access          `json:"read"`
+	Write     Access          `json:"write"`
+	PrivWrite Access          `json:"priv_write"`
+	DeriveKey DeriveKeyAccess `json:"derive_key"`
+
+	GenKey bool       `json:"gen_key"`
+	Sign   SignAccess `json:"sign"`
+	ECDH   Access     `json:"ecdh"`
+
+	// LimitedUse indicates that the uses of the key are limited by the
+	// monotonic counter.
+	LimitedUse bool `json:"limited_use"`
+	// NoMAC prohibits the key from being used by the MAC command.
+	NoMAC bool `json:"no_mac"`
+	// RequireRandom requires a random nonce when using the key.
+	RequireRandom bool `json:"require_random"`
+	// AuthKey is the slot of the key which must be authorized before use.
+	AuthKey *int `json:"auth_key,omitempty"`
+	// PersistentDisable requires the persistent latch to use the key.
+	PersistentDisable bool `json:"persistent_disable"`
+	// Lockable indicates that the slot can be individually locked.
+	Lockable bool `json:"lockable"`
+}
+
+// Explain describes the permissions of the slot.
+func Explain(c *Config608, slot int) (*SlotExplanation, error) {
+	if slot < 0 || slot >= len(c.SlotConfig) {
+		return nil, fmt.Errorf("atecc: invalid slot %d", slot)
+	}
+
+	var (
+		sc = c.SlotConfig[slot]
+		kc = c.KeyConfig[slot]
+
+		// WriteConfig is interpreted per command
+		writeConfig = sc.Bits2 >> 4
+		readKey     = byte(sc.ReadKey())
+		writeKey    = byte(sc.WriteKey())
+	)
+
+	e := &SlotExplanation{
+		Slot:              slot,
+		KeyType:           kc.KeyType(),
+		Private:           kc.Private(),
+		Read:              Access{Mode: AccessNever},
+		Write:             Access{Mode: AccessNever},
+		PrivWrite:         Access{Mode: AccessNever},
+		ECDH:              Access{Mode: AccessNever},
+		LimitedUse:        sc.LimitedUse(),
+		NoMAC:             sc.NoMac(),
+		RequireRandom:     kc.RequireRandom(),
+		PersistentDisable: kc.PersistentDisable(),
+		Lockable:          kc.Lockable(),
+	}
+	if kc.RequireAuth() {
+		authKey := int(kc.AuthKey())
+		e.AuthKey = &authKey
+	}
+
+	if kc.Private() {
+		// private keys are never read and the ReadKey holds the usage bits
+		if writeConfig&0x04 != 0 {
+			e.PrivWrite = newAccess(AccessEncrypted, writeKey)
+		}
+		e.GenKey = writeConfig&0x02 != 0
+		e.Sign = SignAccess{
+			External: readKey&0x01 != 0,
+			Internal: readKey&0x02 != 0,
+		}
+		if readKey&readKeyECDH != 0 {
+			e.ECDH = ecdhAccess(c, slot, readKey)
+		}
+		return e, nil
+	}
+
+	switch {
+	case !sc.IsSecret():
+		e.Read = Access{Mode: AccessClear}
+	case sc.EncryptRead():
+		e.Read = newAccess(AccessEncrypted, readKey)
+	}
+
+	// Write: 0000 always, 0001 PubInvalid, 001x and 10xx never, x1xx encrypt
+	switch {
+	case writeConfig&0x04 != 0:
+		e.Write = newAccess(AccessEncrypted, writeKey)
+	case writeConfig == 0x00:
+		e.Write = Access{Mode: AccessClear}
+	case writeConfig == 0x01:
+		e.Write = Access{Mode: AccessPubInvalid}
+	}
+
+	// DeriveKey: bit 13 enables it, bit 12 creates the key from the parent
+	// WriteKey instead of rolling it and bit 15 requires an authorizing MAC
+	if writeConfig&0x02 != 0 {
+		e.DeriveKey = DeriveKeyAccess{
+			Allowed:    true,
+			RequireMAC: writeConfig&0x08 != 0,
+		}
+		if writeConfig&0x01 != 0 {
+			parent := int(writeKey)
+			e.DeriveKey.Parent = &parent
+		}
+	}
+	return e, nil
+}
+
+// ecdhAccess returns how the ECDH master secret of the slot is output.
+func ecdhAccess(c *Config608, slot int, readKey byte) Access {
+	if readKey&readKeyECDHToSlot != 0 {
+		return newAccess(AccessSlot, byte(slot+1))
+	}
+	switch c.ChipOptions.EcdhProtectionBits() {
+	case 0:
+		return Access{Mode: AccessClear}
+	case 1:
+		return newAccess(AccessEncrypted, c.ChipOptions.IoProtectionKey())
+	default:
+		return Access{Mode: AccessNever}
+	}
+}
@@ -0,0 +1,93 @@
+package ateccconf
+
+import "testing"
+
+func TestExplain(t *testing.T) {
+	testCases := []struct {
+		slot int
+		want map[string]string
+	}{
+		{0, map[string]string{
+			"key_type":   "private",
+			"read":       "never",
+			"write":      "never",
+			"priv_write": "never",
+			"derive_key": "never",
+			"sign":       "external",
+			"ecdh":       "clear",
+		}},
+		{5, map[string]string{
+			"key_type":   "aes",
+			"read":       "encrypted (key 6)",
+			"write":      "encrypted (key 6)",
+			"priv_write": "never",
+			"derive_key": "never",
+			"sign":       "never",
+			"ecdh":       "never",
+		}},
+		{8, map[string]string{
+			"key_type":   "other",
+			"read":       "clear",
+			"write":      "clear",
+			"priv_write": "never",
+			"derive_key": "never",
+			"sign":       "never",
+			"ecdh":       "never",
+		}},
+	}
+
+	c := DefaultConfig608()
+	for _, tc := range testCases {
+		e, err := Explain(c, tc.slot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]string{
+			"key_type":   e.KeyType.String(),
+			"read":       e.Read.String(),
+			"write":      e.Write.String(),
+			"priv_write": e.PrivWrite.String(),
+			"derive_key": e.DeriveKey.String(),
+			"sign":       e.Sign.String(),
+			"ecdh":       e.ECDH.String(),
+		}
+		for k, want := range tc.want {
+			if got[k] != want {
+				t.Errorf("slot %d %s: got %q, want %q", tc.slot, k, got[k], want)
+			}
+		}
+	}
+
+	// private keys which can be regenerated or replaced
+	c.SlotConfig[0] = c.SlotConfig[0].WithWriteKey(3).WithWriteConfig(SlotWriteConfig{GenKeyEnabled: true, Unknown2: 1})
+	if e, _ := Explain(c, 0); !e.GenKey || e.PrivWrite.String() != "encrypted (key 3)" {
+		t.Errorf("got gen key %v and priv write %s", e.GenKey, e.PrivWrite)
+	}
+
+	// keys derived from a parent key with an authorizing MAC
+	c.SlotConfig[9] = c.SlotConfig[9].WithWriteKey(4).WithWriteConfig(SlotWriteConfig{Unknown: true, GenKeyEnabled: true, Unknown2: 3})
+	if e, _ := Explain(c, 9); e.DeriveKey.String() != "create (parent 4), mac" || e.Write.String() != "encrypted (key 4)" {
+		t.Errorf("got derive key %s and write %s", e.DeriveKey, e.Write)
+	}
+
+	// keys rolled with an authorizing MAC and never written
+	c.SlotConfig[9] = c.SlotConfig[9].WithWriteConfig(SlotWriteConfig{GenKeyEnabled: true, Unknown2: 2})
+	if e, _ := Explain(c, 9); e.DeriveKey.String() != "roll, mac" || e.Write.Mode != AccessNever {
+		t.Errorf("got derive key %s and write %s", e.DeriveKey, e.Write)
+	}
+
+	// keys created from a parent key without a MAC, written in the clear
+	// while the public key is invalid
+	c.SlotConfig[9] = c.SlotConfig[9].WithWriteConfig(SlotWriteConfig{Unknown: true})
+	if e, _ := Explain(c, 9); e.DeriveKey.String() != "never" || e.Write.Mode != AccessPubInvalid {
+		t.Errorf("got derive key %s and write %s", e.DeriveKey, e.Write)
+	}
+	c.SlotConfig[9] = c.SlotConfig[9].WithWriteConfig(SlotWriteConfig{Unknown: true, GenKeyEnabled: true})
+	if e, _ := Explain(c, 9); e.DeriveKey.String() != "create (parent 4)" || e.Write.Mode != AccessNever {
+		t.Errorf("got derive key %s and write %s", e.DeriveKey, e.Write)
+	}
+
+	if _, err := Explain(c, 16); err == nil {
+		t.Error("expected error for invalid slot")
+	}
+}
@@ -0,0 +1,89 @@
+package ateccconf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalConfig608 decodes either a full configuration zone, or one without
+// the first 16 bytes programmed by the factory.
+func UnmarshalConfig608(config []byte) (*Config608, error) {
+	var conf Config608
+	switch len(config) {
+	case ConfigSize608:
+		if err := Unmarshal(config, &conf); err != nil {
+			return nil, err
+		}
+	case ConfigSize608 - PermanentOffset608:
+		if err := UnmarshalPartial(config, PermanentOffset608, &conf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("atecc: invalid config size %d", len(config))
+	}
+	return &conf, nil
+}
+
+// TODO: parse configurations exported by Trust Platform Design Suite (TPDS)
+// as XML, once a real export is available to test against.
+
+// ParseHex decodes a hex encoded configuration.
+//
+// Whitespace is ignored, as are 0x prefixes and commas separating the bytes.
+func ParseHex(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, tok := range strings.FieldsFunc(string(data), isHexSeparator) {
+		tok = strings.TrimPrefix(strings.TrimPrefix(tok, "0x"), "0X")
+		b, err := hex.DecodeString(tok)
+		if err != nil {
+			return nil, fmt.Errorf("atecc: invalid hex config: %w", err)
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+func isHexSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+var cComment = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+
+// ParseCArray decodes the first array initializer in C source, such as the
+// configuration arrays used with cryptoauthlib:
+//
+//	const uint8_t test_ecc608_configdata[ATCA_ECC_CONFIG_SIZE] = {
+//	    0x01, 0x23, 0x00, 0x00, // ...
+//	};
+func ParseCArray(src []byte) ([]byte, error) {
+	s := cComment.ReplaceAllString(string(src), "")
+	if i := strings.Index(s, "="); i >= 0 {
+		s = s[i+1:]
+	}
+
+	start := strings.Index(s, "{")
+	end := strings.Index(s, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("atecc: no array initializer found")
+	}
+
+	var config []byte
+	for _, tok := range strings.Split(s[start+1:end], ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			continue
+		}
+		// C integer literals, e.g. 0x6A, 106 or 0152
+		b, err := strconv.ParseUint(tok, 0, 8)
+		if err != nil {
+			return nil, fmt.Errorf("atecc: invalid array element %q", tok)
+		}
+		config = append(config, byte(b))
+	}
+	return config, nil
+}
@@ -0,0 +1,78 @@
+package ateccconf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	// cArray formats the config as cryptoauthlib test configs are written.
+	var cArray strings.Builder
+	cArray.WriteString("const uint8_t test_ecc608_configdata[ATCA_ECC_CONFIG_SIZE] = {\n")
+	for i, b := range golden608 {
+		fmt.Fprintf(&cArray, "0x%02X, ", b)
+		if i%16 == 15 {
+			fmt.Fprintf(&cArray, "//%d\n", i)
+		}
+	}
+	cArray.WriteString("/* the end */ };\n")
+
+	testCases := []struct {
+		name  string
+		parse func([]byte) ([]byte, error)
+		in    string
+		want  []byte
+	}{
+		{"hex", ParseHex, "6A 00 00 01\n85 00  82 00", Default608[:8]},
+		{"hex/prefix", ParseHex, "0x6a, 0x00,0x00, 0x01", Default608[:4]},
+		{"hex/header", ParseHex, fmt.Sprintf("% x", golden608), golden608},
+		{"c", ParseCArray, cArray.String(), golden608},
+		{"c/decimal", ParseCArray, "uint8_t c[] = {106, 0, 0x00, 01,};", Default608[:4]},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.parse([]byte(tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf(" got: %x", got)
+				t.Errorf("want: %x", tc.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name  string
+		parse func([]byte) ([]byte, error)
+		in    string
+	}{
+		{"hex/odd", ParseHex, "6A 0"},
+		{"c/range", ParseCArray, "uint8_t c[] = {0x100};"},
+		{"c/missing", ParseCArray, "uint8_t c[];"},
+	}
+	for _, tc := range invalid {
+		if _, err := tc.parse([]byte(tc.in)); err == nil {
+			t.Errorf("%s: expected error for %q", tc.name, tc.in)
+		}
+	}
+}
+
+func TestUnmarshalConfig608(t *testing.T) {
+	full, err := UnmarshalConfig608(golden608)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial, err := UnmarshalConfig608(Default608)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full.SlotConfig != partial.SlotConfig || full.SN03 == partial.SN03 {
+		t.Errorf("got %v and %v", full, partial)
+	}
+	if _, err := UnmarshalConfig608(Default608[:8]); err == nil {
+		t.Error("expected error for partial config")
+	}
+}
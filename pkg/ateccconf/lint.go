@@ -0,0 +1,235 @@
+package ateccconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a lint finding.
+type Severity uint8
+
+const (
+	// SeverityInfo is a setting worth knowing about, but often intended.
+	SeverityInfo = Severity(iota)
+	// SeverityWarning is a risky setting which should be reviewed.
+	SeverityWarning
+	// SeverityError is a setting which is inconsistent or insecure.
+	SeverityError
+)
+
+var severities = []Severity{SeverityInfo, SeverityWarning, SeverityError}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return marshalEnum(s, severities)
+}
+
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, s, severities)
+}
+
+// ParseSeverity returns the severity with the given name.
+func ParseSeverity(name string) (Severity, error) {
+	for _, s := range severities {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("atecc: unknown severity %q", name)
+}
+
+// Checks reported by Lint.
+const (
+	CheckKeyType              = "key-type"
+	CheckPrivateKeyNotSecret  = "private-key-not-secret"
+	CheckPrivateKeyPrivWrite  = "private-key-priv-write"
+	CheckEncryptReadNotSecret = "encrypt-read-not-secret"
+	CheckReadClear            = "read-clear"
+	CheckWriteForever         = "write-forever"
+	CheckI2CAddress           = "i2c-address"
+	CheckIOProtection         = "io-protection"
+)
+
+// Finding is a risky or inconsistent setting found by Lint.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Check is the name of the check reporting the finding.
+	Check string `json:"check"`
+	// Field is the JSON path of the setting, e.g. slot_config[3].
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Field, f.Message)
+}
+
+// knownI2CAddresses are the 7-bit default addresses of other common parts,
+// which may share the bus with the device. The ATECC608 defaults, such as
+// 0x60, are left out as the configuration is meant to use them.
+var knownI2CAddresses = map[byte]string{
+	0x33: "ECC204",
+	0x50: "24Cxx EEPROM",
+	0x64: "ATSHA204A",
+	0x68: "DS1307/DS3231 RTC",
+}
+
+// Lint reviews the configuration for risky or inconsistent settings.
+//
+// No findings does not imply that the configuration is secure for a given
+// use, only that none of the known pitfalls were found.
+func Lint(c *Config608) []Finding {
+	var l linter
+	l.i2cAddress(c)
+	for slot := range c.SlotConfig {
+		l.slot(c, slot)
+	}
+	l.ioProtection(c)
+	return l.findings
+}
+
+type linter struct {
+	findings []Finding
+}
+
+func (l *linter) add(severity Severity, check, field, format string, args ...any) {
+	l.findings = append(l.findings, Finding{
+		Severity: severity,
+		Check:    check,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) i2cAddress(c *Config608) {
+	// the address is stored in the upper 7 bits
+	addr := c.I2CAddress >> 1
+	if addr < 0x08 || addr > 0x77 {
+		l.add(SeverityError, CheckI2CAddress, "i2c_address", "address 0x%02x is reserved by the I2C specification", addr)
+	} else if part, ok := knownI2CAddresses[addr]; ok {
+		l.add(SeverityWarning, CheckI2CAddress, "i2c_address", "address 0x%02x collides with the default address of %s", addr, part)
+	}
+}
+
+// ECDH bits of the SlotConfig ReadKey for private keys.
+const (
+	readKeyECDH       = 0x04
+	readKeyECDHToSlot = 0x08
+)
+
+// usesECDH returns true if the slot may output an ECDH master secret.
+func usesECDH(sc SlotConfig, kc KeyConfig) bool {
+	return kc.Private() && sc.ReadKey()&readKeyECDH != 0 && sc.ReadKey()&readKeyECDHToSlot == 0
+}
+
+// usesKDF returns true if the slot may be the source key of KDF, which is a
+// secret AES or SHA key.
+func usesKDF(sc SlotConfig, kc KeyConfig) bool {
+	kt := kc.KeyType()
+	return !kc.Private() && sc.IsSecret() && (kt == KeyTypeAES || kt == KeyTypeOther)
+}
+
+func (l *linter) slot(c *Config608, i int) {
+	var (
+		sc        = c.SlotConfig[i]
+		kc        = c.KeyConfig[i]
+		slotField = fmt.Sprintf("slot_config[%d]", i)
+		keyField  = fmt.Sprintf("key_config[%d]", i)
+
+		// WriteConfig is interpreted per command, see the datasheet
+		writeConfig = sc.Bits2 >> 4
+	)
+
+	known := false
+	for _, kt := range keyTypes {
+		known = known || kc.KeyType() == kt
+	}
+	if !known {
+		l.add(SeverityWarning, CheckKeyType, keyField, "key type %d is reserved", kc.KeyType())
+	}
+
+	if kc.Private() {
+		if kc.KeyType() != KeyTypePrivate {
+			l.add(SeverityError, CheckKeyType, keyField, "private key has key type %s, want %s", kc.KeyType(), KeyTypePrivate)
+		}
+		if !sc.IsSecret() {
+			l.add(SeverityError, CheckPrivateKeyNotSecret, slotField, "private key is not secret and may be exported")
+		}
+		if writeConfig&0x04 != 0 {
+			l.add(SeverityWarning, CheckPrivateKeyPrivWrite, slotField, "private key can be overwritten using PrivWrite")
+		}
+		return
+	}
+
+	if sc.EncryptRead() && !sc.IsSecret() {
+		l.add(SeverityError, CheckEncryptReadNotSecret, slotField, "encrypted reads require the slot to be secret")
+	} else if !sc.IsSecret() && sc.ReadKey() != 0 {
+		l.add(SeverityInfo, CheckReadClear, slotField, "slot has read key %d but is not secret and can be read in the clear", sc.ReadKey())
+	}
+
+	if writeConfig == 0 && !kc.Lockable() {
+		severity := SeverityWarning
+		if sc.IsSecret() {
+			severity = SeverityError
+		}
+		l.add(severity, CheckWriteForever, slotField, "slot can always be written in the clear and cannot be locked")
+	}
+}
+
+func (l *linter) ioProtection(c *Config608) {
+	const field = "chip_options"
+
+	var ecdh, kdf bool
+	for i := range c.SlotConfig {
+		ecdh = ecdh || usesECDH(c.SlotConfig[i], c.KeyConfig[i])
+		kdf = kdf || usesKDF(c.SlotConfig[i], c.KeyConfig[i])
+	}
+
+	var (
+		co        = c.ChipOptions
+		clear     []string
+		encrypted []string
+	)
+	for _, p := range []struct {
+		name string
+		bits byte
+		used bool
+	}{
+		{"ECDH", co.EcdhProtectionBits(), ecdh},
+		{"KDF", co.KdfProtectionBits(), kdf},
+	} {
+		switch p.bits {
+		case 0:
+			if p.used {
+				clear = append(clear, p.name)
+			}
+		case 1:
+			encrypted = append(encrypted, p.name)
+		case 3:
+			l.add(SeverityError, CheckIOProtection, field, "%s protection bits are reserved", p.name)
+		}
+	}
+
+	if len(clear) > 0 {
+		severity := SeverityWarning
+		if co.IoProtectionKeyEnabled() {
+			severity = SeverityInfo
+		}
+		l.add(severity, CheckIOProtection, field, "%s output is permitted in the clear", strings.Join(clear, " and "))
+	}
+	if len(encrypted) > 0 && !co.IoProtectionKeyEnabled() {
+		l.add(SeverityError, CheckIOProtection, field, "%s output must be encrypted but the IO protection key is disabled", strings.Join(encrypted, " and "))
+	}
+}
@@ -0,0 +1,100 @@
+package ateccconf
+
+import "testing"
+
+func TestLint(t *testing.T) {
+	// base is a configuration without findings.
+	base := func() *Config608 {
+		c := DefaultConfig608()
+		c.I2CAddress = 0x61 << 1
+		for i := range c.SlotConfig {
+			c.SlotConfig[i] = c.SlotConfig[i].WithReadKey(0)
+			c.KeyConfig[i] = c.KeyConfig[i].WithLockable(true)
+		}
+		c.ChipOptions = c.ChipOptions.WithIoProtectionKeyEnabled(true).WithKdfProtectionBits(2)
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		modify   func(c *Config608)
+		check    string
+		field    string
+		severity Severity
+	}{
+		{"private key type", func(c *Config608) {
+			c.KeyConfig[0] = c.KeyConfig[0].WithKeyType(KeyTypeAES)
+		}, CheckKeyType, "key_config[0]", SeverityError},
+		{"reserved key type", func(c *Config608) {
+			c.KeyConfig[9] = c.KeyConfig[9].WithKeyType(1)
+		}, CheckKeyType, "key_config[9]", SeverityWarning},
+		{"private key not secret", func(c *Config608) {
+			c.SlotConfig[1] = c.SlotConfig[1].WithIsSecret(false)
+		}, CheckPrivateKeyNotSecret, "slot_config[1]", SeverityError},
+		{"private key priv write", func(c *Config608) {
+			c.SlotConfig[2] = c.SlotConfig[2].WithWriteConfig(SlotWriteConfig{Unknown2: 1})
+		}, CheckPrivateKeyPrivWrite, "slot_config[2]", SeverityWarning},
+		{"encrypt read", func(c *Config608) {
+			c.SlotConfig[8] = c.SlotConfig[8].WithEncryptRead(true)
+		}, CheckEncryptReadNotSecret, "slot_config[8]", SeverityError},
+		{"read clear", func(c *Config608) {
+			c.SlotConfig[8] = c.SlotConfig[8].WithReadKey(3)
+		}, CheckReadClear, "slot_config[8]", SeverityInfo},
+		{"write forever", func(c *Config608) {
+			c.KeyConfig[8] = c.KeyConfig[8].WithLockable(false)
+		}, CheckWriteForever, "slot_config[8]", SeverityWarning},
+		{"write secret forever", func(c *Config608) {
+			c.KeyConfig[9] = c.KeyConfig[9].WithLockable(false)
+		}, CheckWriteForever, "slot_config[9]", SeverityError},
+		{"i2c address collision", func(c *Config608) {
+			c.I2CAddress = 0x64 << 1
+		}, CheckI2CAddress, "i2c_address", SeverityWarning},
+		{"i2c address reserved", func(c *Config608) {
+			c.I2CAddress = 0x02
+		}, CheckI2CAddress, "i2c_address", SeverityError},
+		{"kdf clear", func(c *Config608) {
+			c.ChipOptions = c.ChipOptions.WithIoProtectionKeyEnabled(false).WithKdfProtectionBits(0)
+		}, CheckIOProtection, "chip_options", SeverityWarning},
+		{"ecdh encrypted", func(c *Config608) {
+			c.ChipOptions = c.ChipOptions.WithIoProtectionKeyEnabled(false).WithEcdhProtectionBits(1)
+		}, CheckIOProtection, "chip_options", SeverityError},
+	}
+
+	if f := Lint(base()); len(f) != 0 {
+		t.Fatalf("unexpected findings: %v", f)
+	}
+
+	// the default ATECC608 address does not collide with itself
+	c := base()
+	c.I2CAddress = 0x60 << 1
+	if f := Lint(c); len(f) != 0 {
+		t.Errorf("unexpected findings for default address: %v", f)
+	}
+
+	// KDF output in the clear is fine without any secret KDF source keys
+	c = base()
+	c.ChipOptions = c.ChipOptions.WithKdfProtectionBits(0)
+	for i := range c.SlotConfig {
+		if !c.KeyConfig[i].Private() {
+			c.SlotConfig[i] = c.SlotConfig[i].WithIsSecret(false).WithEncryptRead(false)
+		}
+	}
+	if f := Lint(c); len(f) != 0 {
+		t.Errorf("unexpected findings without kdf keys: %v", f)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := base()
+			tc.modify(c)
+
+			f := Lint(c)
+			if len(f) != 1 {
+				t.Fatalf("got %d findings, want 1: %v", len(f), f)
+			}
+			if f[0].Check != tc.check || f[0].Field != tc.field || f[0].Severity != tc.severity {
+				t.Errorf("got %v (%s), want %s: %s: %s", f[0], f[0].Check, tc.severity, tc.field, tc.check)
+			}
+		})
+	}
+}
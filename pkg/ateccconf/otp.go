@@ -0,0 +1,253 @@
+package ateccconf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// OTPSize is the size of the OTP zone.
+const OTPSize = 64
+
+// OTPMode is the mode of the OTP zone of ATSHA204 and ATECC508 devices, which
+// decides how it can be accessed once the data zone is locked.
+type OTPMode byte
+
+const (
+	// OTPModeLegacy prohibits writes and only permits 4-byte reads.
+	OTPModeLegacy = OTPMode(0x00)
+	// OTPModeConsumption permits writes which only clear bits.
+	OTPModeConsumption = OTPMode(0x55)
+	// OTPModeReadOnly prohibits writes. ATECC608 devices always use it.
+	OTPModeReadOnly = OTPMode(0xaa)
+)
+
+var otpModes = []OTPMode{OTPModeLegacy, OTPModeConsumption, OTPModeReadOnly}
+
+func (m OTPMode) String() string {
+	switch m {
+	case OTPModeLegacy:
+		return "legacy"
+	case OTPModeConsumption:
+		return "consumption"
+	case OTPModeReadOnly:
+		return "read-only"
+	default:
+		return "unknown"
+	}
+}
+
+func (m OTPMode) MarshalJSON() ([]byte, error) {
+	return marshalEnum(m, otpModes)
+}
+
+func (m *OTPMode) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, m, otpModes)
+}
+
+// OTPUsage describes how the OTP zone can be accessed.
+type OTPUsage struct {
+	Mode       OTPMode `json:"mode"`
+	DataLocked bool    `json:"data_locked"`
+	// ReadSizes are the permitted read sizes in bytes, none if prohibited.
+	ReadSizes []int `json:"read_sizes"`
+	// WriteSizes are the permitted write sizes in bytes, none if prohibited.
+	WriteSizes []int `json:"write_sizes"`
+	// ClearOnly indicates that writes can only clear bits, from one to zero.
+	ClearOnly bool `json:"clear_only"`
+}
+
+// CanRead returns true if reads are permitted.
+func (u OTPUsage) CanRead() bool {
+	return len(u.ReadSizes) > 0
+}
+
+// CanWrite returns true if writes are permitted.
+func (u OTPUsage) CanWrite() bool {
+	return len(u.WriteSizes) > 0
+}
+
+func (u OTPUsage) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s mode", u.Mode)
+	if !u.DataLocked {
+		s.WriteString(", data zone unlocked")
+	}
+	fmt.Fprintf(&s, ", read %s, write %s", formatSizes(u.ReadSizes), formatSizes(u.WriteSizes))
+	if u.ClearOnly {
+		s.WriteString(" clearing bits only")
+	}
+	return s.String()
+}
+
+func formatSizes(sizes []int) string {
+	if len(sizes) == 0 {
+		return "never"
+	}
+	s := make([]string, len(sizes))
+	for i, size := range sizes {
+		s[i] = fmt.Sprint(size)
+	}
+	return strings.Join(s, " or ") + " bytes"
+}
+
+func newOTPUsage(mode OTPMode, dataLocked LockState) OTPUsage {
+	u := OTPUsage{Mode: mode, DataLocked: dataLocked.IsLocked()}
+	switch {
+	case !u.DataLocked:
+		u.WriteSizes = []int{32}
+	case mode == OTPModeLegacy:
+		u.ReadSizes = []int{4}
+	case mode == OTPModeConsumption:
+		u.ReadSizes = []int{4, 32}
+		u.WriteSizes = []int{4, 32}
+		u.ClearOnly = true
+	default:
+		u.ReadSizes = []int{4, 32}
+	}
+	return u
+}
+
+// OTPUsage describes the OTP zone, which is read-only once the data zone is
+// locked.
+func (c *Config608) OTPUsage() OTPUsage {
+	return newOTPUsage(OTPModeReadOnly, c.LockValue)
+}
+
+// OTPUsage describes the OTP zone given the OTP mode.
+func (c *Config508) OTPUsage() OTPUsage {
+	return newOTPUsage(c.OTPMode, c.LockValue)
+}
+
+// OTPUsage describes the OTP zone given the OTP mode.
+func (c *Config204) OTPUsage() OTPUsage {
+	return newOTPUsage(c.OTPMode, c.LockValue)
+}
+
+// Formats of an OTPField.
+const (
+	OTPFormatHex    = "hex"
+	OTPFormatString = "string"
+	OTPFormatUint   = "uint"
+)
+
+// OTPField is a named value stored in the OTP zone, such as a hardware
+// revision or a manufacturing lot.
+type OTPField struct {
+	Name   string `json:"name"`
+	Offset int    `json:"offset"`
+	Size   int    `json:"size"`
+	// Format is how the value is encoded: hex (default) for raw bytes,
+	// string for text padded with zeros or uint for a big endian integer.
+	Format string `json:"format,omitempty"`
+}
+
+// OTPLayout describes the fields stored in the OTP zone.
+type OTPLayout []OTPField
+
+// Validate checks that the fields are within the zone and do not overlap.
+func (l OTPLayout) Validate() error {
+	var used [OTPSize]string
+	for _, f := range l {
+		if f.Name == "" {
+			return errors.New("atecc: otp field without name")
+		}
+		switch f.Format {
+		case "", OTPFormatHex, OTPFormatString, OTPFormatUint:
+		default:
+			return fmt.Errorf("atecc: otp field %s has unknown format %q", f.Name, f.Format)
+		}
+		if f.Offset < 0 || f.Size <= 0 || f.Offset+f.Size > OTPSize {
+			return fmt.Errorf("atecc: otp field %s is outside of the zone", f.Name)
+		}
+		for i := f.Offset; i < f.Offset+f.Size; i++ {
+			if used[i] != "" {
+				return fmt.Errorf("atecc: otp fields %s and %s overlap", used[i], f.Name)
+			}
+			used[i] = f.Name
+		}
+	}
+	return nil
+}
+
+// field returns the field with the name.
+func (l OTPLayout) field(name string) (OTPField, error) {
+	for _, f := range l {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return OTPField{}, fmt.Errorf("atecc: unknown otp field %q", name)
+}
+
+// OTPValue is the decoded value of an OTPField.
+type OTPValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Decode returns the values of the fields in the OTP zone.
+func (l OTPLayout) Decode(otp []byte) ([]OTPValue, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+	if len(otp) != OTPSize {
+		return nil, fmt.Errorf("atecc: invalid otp size %d", len(otp))
+	}
+
+	values := make([]OTPValue, len(l))
+	for i, f := range l {
+		b := otp[f.Offset : f.Offset+f.Size]
+		v := OTPValue{Name: f.Name}
+		switch f.Format {
+		case OTPFormatString:
+			v.Value = string(bytes.TrimRight(b, "\x00"))
+		case OTPFormatUint:
+			v.Value = new(big.Int).SetBytes(b).String()
+		default:
+			v.Value = hex.EncodeToString(b)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Encode sets the value of the named field in the OTP zone.
+func (l OTPLayout) Encode(otp []byte, name, value string) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	if len(otp) != OTPSize {
+		return fmt.Errorf("atecc: invalid otp size %d", len(otp))
+	}
+	f, err := l.field(name)
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, f.Size)
+	switch f.Format {
+	case OTPFormatString:
+		if len(value) > f.Size {
+			return fmt.Errorf("atecc: otp field %s exceeds %d bytes", f.Name, f.Size)
+		}
+		copy(b, value)
+	case OTPFormatUint:
+		n, ok := new(big.Int).SetString(value, 0)
+		if !ok || n.Sign() < 0 || (n.BitLen()+7)/8 > f.Size {
+			return fmt.Errorf("atecc: invalid value %q of otp field %s", value, f.Name)
+		}
+		n.FillBytes(b)
+	default:
+		v, err := hex.DecodeString(value)
+		if err != nil || len(v) != f.Size {
+			return fmt.Errorf("atecc: otp field %s must be %d hex encoded bytes", f.Name, f.Size)
+		}
+		copy(b, v)
+	}
+	copy(otp[f.Offset:], b)
+	return nil
+}
@@ -0,0 +1,100 @@
+package ateccconf
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOTPUsage(t *testing.T) {
+	testCases := []struct {
+		mode       OTPMode
+		lock       LockState
+		want       string
+		clearOnly  bool
+		canRead    bool
+		canWrite   bool
+		jsonString string
+	}{
+		{OTPModeReadOnly, LockStateUnlocked, "read-only mode, data zone unlocked, read never, write 32 bytes", false, false, true, `"read-only"`},
+		{OTPModeReadOnly, LockStateLocked, "read-only mode, read 4 or 32 bytes, write never", false, true, false, `"read-only"`},
+		{OTPModeConsumption, LockStateLocked, "consumption mode, read 4 or 32 bytes, write 4 or 32 bytes clearing bits only", true, true, true, `"consumption"`},
+		{OTPModeLegacy, LockStateLocked, "legacy mode, read 4 bytes, write never", false, true, false, `"legacy"`},
+		{OTPMode(0x12), LockStateLocked, "unknown mode, read 4 or 32 bytes, write never", false, true, false, `18`},
+	}
+	for _, tc := range testCases {
+		c := Config508{OTPMode: tc.mode, LockValue: tc.lock}
+		u := c.OTPUsage()
+		if got := u.String(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+		if u.ClearOnly != tc.clearOnly || u.CanRead() != tc.canRead || u.CanWrite() != tc.canWrite {
+			t.Errorf("%s: got %+v", tc.want, u)
+		}
+
+		b, err := json.Marshal(tc.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.jsonString {
+			t.Errorf("got %s, want %s", b, tc.jsonString)
+		}
+	}
+
+	c := Config608{LockValue: LockStateLocked}
+	if u := c.OTPUsage(); u.Mode != OTPModeReadOnly || u.CanWrite() {
+		t.Errorf("got ATECC608 usage %s", u)
+	}
+}
+
+func TestOTPLayout(t *testing.T) {
+	layout := OTPLayout{
+		{Name: "revision", Offset: 0, Size: 2, Format: OTPFormatUint},
+		{Name: "lot", Offset: 4, Size: 12, Format: OTPFormatString},
+		{Name: "id", Offset: 32, Size: 4},
+	}
+
+	otp := make([]byte, OTPSize)
+	for _, v := range []OTPValue{{"revision", "0x102"}, {"lot", "L2024-17"}, {"id", "deadbeef"}} {
+		if err := layout.Encode(otp, v.Name, v.Value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if otp[0] != 0x01 || otp[1] != 0x02 || string(otp[4:12]) != "L2024-17" || otp[32] != 0xde {
+		t.Errorf("got otp %x", otp)
+	}
+
+	values, err := layout.Decode(otp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []OTPValue{{"revision", "258"}, {"lot", "L2024-17"}, {"id", "deadbeef"}}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("got %+v, want %+v", values[i], want[i])
+		}
+	}
+
+	for _, invalid := range []struct{ name, value string }{
+		{"revision", "0x10000"},
+		{"revision", "-1"},
+		{"lot", "a lot name which is too long"},
+		{"id", "dead"},
+		{"unknown", "00"},
+	} {
+		if err := layout.Encode(otp, invalid.name, invalid.value); err == nil {
+			t.Errorf("expected error for %s=%s", invalid.name, invalid.value)
+		}
+	}
+
+	for _, invalid := range []OTPLayout{
+		{{Name: "", Offset: 0, Size: 1}},
+		{{Name: "a", Offset: 60, Size: 8}},
+		{{Name: "a", Offset: 0, Size: 0}},
+		{{Name: "a", Offset: 0, Size: 4, Format: "base64"}},
+		{{Name: "a", Offset: 0, Size: 4}, {Name: "b", Offset: 3, Size: 4}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ateccconf
+
+import (
+	"embed"
+	"fmt"
+	"path"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// ConfigTemplate is a named configuration for ATECC608 devices.
+type ConfigTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Roles describes the intended use of each slot.
+	Roles [16]string `json:"roles"`
+
+	// file is the hex encoded configuration, without the first 16 bytes.
+	file string
+}
+
+// TODO: add the Microchip TNGTLS and TFLXTLS configurations, and the TLS and
+// TEST configurations of cryptoauthlib. Their bytes must be copied from a
+// cryptoauthlib checkout, not retyped.
+var configTemplates = []ConfigTemplate{
+	{
+		Name:        "adafruit",
+		Description: "Configuration used by the Adafruit CircuitPython ATECC library",
+		Roles: [16]string{
+			0:  "Private key for signing and certificate requests, regenerated with GenKey",
+			1:  "Private key for signing and ECDH, regenerated with GenKey",
+			2:  "Private key for signing and ECDH, regenerated with GenKey",
+			3:  "Private key for signing and ECDH, regenerated with GenKey",
+			4:  "Private key for signing and ECDH, regenerated with GenKey",
+			5:  "Secret key, never read and only written before the data zone is locked",
+			6:  "Secret key, not usable by MAC",
+			7:  "Secret key with limited use",
+			8:  "General data, such as certificates",
+			9:  "General data",
+			10: "General data",
+			11: "General data",
+			12: "General data",
+			13: "General data",
+			14: "General data",
+			15: "Secret key with limited use",
+		},
+		file: "608/adafruit.hex",
+	},
+	{
+		Name:        "cryptoauthtools",
+		Description: "Configuration used in the cryptoauthtools repository and config.py",
+		Roles: [16]string{
+			0:  "Primary private key, external signing and ECDH",
+			1:  "Private key for internal signing",
+			2:  "Private key, regenerated with GenKey",
+			3:  "Private key, regenerated with GenKey",
+			4:  "Private key, regenerated with GenKey",
+			5:  "AES key, encrypted read and write using slot 6",
+			6:  "IO protection key",
+			7:  "Secure boot digest",
+			8:  "General data",
+			9:  "AES key",
+			10: "General data",
+			11: "Public key",
+			12: "General data",
+			13: "Public key",
+			14: "Validated public key",
+			15: "Secure boot public key",
+		},
+		file: "608/cryptoauthtools.hex",
+	},
+	{
+		Name:        "pkcs11",
+		Description: "Configuration used by the cryptoauthlib PKCS#11 provider",
+		Roles: [16]string{
+			0:  "Private key, ECDH master secret written to slot 1",
+			1:  "ECDH master secret, encrypted read using slot 4",
+			2:  "Private key, regenerated with GenKey",
+			3:  "Private key, regenerated with GenKey",
+			4:  "Read and write encryption key",
+			5:  "Secret data, encrypted read using slot 4",
+			6:  "Secret data",
+			7:  "Private key for internal signing",
+			8:  "General data",
+			9:  "Secret data, encrypted read and write using slot 4",
+			10: "General data",
+			11: "Public key",
+			12: "General data",
+			13: "General data",
+			14: "General data",
+			15: "Public key",
+		},
+		file: "608/pkcs11.hex",
+	},
+}
+
+// Templates returns all embedded configuration templates.
+func Templates() []ConfigTemplate {
+	return append([]ConfigTemplate(nil), configTemplates...)
+}
+
+// Template returns the embedded configuration template with the name.
+func Template(name string) (*ConfigTemplate, error) {
+	for _, t := range configTemplates {
+		if t.Name == name {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("atecc: unknown template %q", name)
+}
+
+// Bytes returns the configuration without the first 16 bytes, which are
+// programmed by the factory.
+func (t ConfigTemplate) Bytes() ([]byte, error) {
+	data, err := templateFS.ReadFile(path.Join("templates", t.file))
+	if err != nil {
+		return nil, err
+	}
+	return ParseHex(data)
+}
+
+// Config returns the configuration of the template.
+func (t ConfigTemplate) Config() (*Config608, error) {
+	b, err := t.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var conf Config608
+	if err := UnmarshalPartial(b, PermanentOffset608, &conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
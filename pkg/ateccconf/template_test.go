@@ -0,0 +1,45 @@
+package ateccconf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	for _, tmpl := range Templates() {
+		t.Run(tmpl.Name, func(t *testing.T) {
+			b, err := tmpl.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			} else if len(b) != len(Default608) {
+				t.Errorf("got %d bytes, want %d", len(b), len(Default608))
+			}
+			c, err := tmpl.Config()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range Lint(c) {
+				if f.Check == CheckKeyType {
+					t.Errorf("unexpected finding: %v", f)
+				}
+			}
+			for slot, role := range tmpl.Roles {
+				if role == "" {
+					t.Errorf("slot %d has no role", slot)
+				}
+			}
+		})
+	}
+
+	tmpl, err := Template("cryptoauthtools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := tmpl.Bytes(); !bytes.Equal(b, Default608) {
+		t.Errorf("got %x, want %x", b, Default608)
+	}
+
+	if _, err := Template("unknown"); err == nil {
+		t.Error("expected error for unknown template")
+	}
+}
@@ -0,0 +1,117 @@
+package manifest
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"fmt"
+	"time"
+
+	"github.com/northvolt/go-atecc/pkg/atecc"
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+// Device is the part of atecc.Dev used to create and verify manifests.
+type Device interface {
+	DeviceType() atecc.DeviceType
+	Variant() atecc.Variant
+	SerialNumber(ctx context.Context) ([]byte, error)
+	PublicKey(ctx context.Context, slot uint8) (crypto.PublicKey, error)
+}
+
+var _ Device = (*atecc.Dev)(nil)
+
+// PrivateKeySlots returns the slots holding P-256 private keys.
+func PrivateKeySlots(c *ateccconf.Config608) []int {
+	var slots []int
+	for slot, kc := range c.KeyConfig {
+		if kc.Private() && kc.KeyType() == ateccconf.KeyTypePrivate {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// FromDevice returns the secure element of the device, with the public keys
+// of the slots.
+//
+// The model and part number are set from the device type and variant, and
+// the provisioning timestamp to now. Other fields are left for the caller.
+func FromDevice(ctx context.Context, d Device, slots []int) (*SecureElement, error) {
+	sn, err := d.SerialNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	se := &SecureElement{
+		Version:               Version,
+		Model:                 d.DeviceType().String(),
+		ProvisioningTimestamp: time.Now().UTC().Truncate(time.Millisecond),
+		UniqueID:              UniqueID(sn),
+		PublicKeySet:          KeySet{Keys: []Key{}},
+	}
+	if v := d.Variant(); v != atecc.VariantUnknown {
+		se.PartNumber = v.String()
+	}
+
+	for _, slot := range slots {
+		pub, err := devicePublicKey(ctx, d, slot)
+		if err != nil {
+			return nil, err
+		}
+		k, err := NewKey(slot, pub)
+		if err != nil {
+			return nil, err
+		}
+		se.PublicKeySet.Keys = append(se.PublicKeySet.Keys, k)
+	}
+	return se, nil
+}
+
+// VerifyDevice verifies that the secure element describes the device.
+//
+// The unique ID must match the serial number, and the public key of each key
+// must match the one computed by the device from the private key in the slot.
+func VerifyDevice(ctx context.Context, d Device, se *SecureElement) error {
+	sn, err := d.SerialNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if id := UniqueID(sn); id != se.UniqueID {
+		return fmt.Errorf("atecc: device %s does not match unique id %s", id, se.UniqueID)
+	}
+
+	for _, k := range se.PublicKeySet.Keys {
+		slot, err := k.Slot()
+		if err != nil {
+			return err
+		}
+		want, err := k.PublicKey()
+		if err != nil {
+			return err
+		}
+		pub, err := devicePublicKey(ctx, d, slot)
+		if err != nil {
+			return err
+		}
+		if !pub.Equal(want) {
+			return fmt.Errorf("atecc: public key of slot %d does not match", slot)
+		}
+	}
+	return nil
+}
+
+func devicePublicKey(ctx context.Context, d Device, slot int) (*ecdsa.PublicKey, error) {
+	if slot < 0 || slot > 15 {
+		return nil, fmt.Errorf("atecc: invalid slot %d", slot)
+	}
+	pub, err := d.PublicKey(ctx, uint8(slot))
+	if err != nil {
+		return nil, fmt.Errorf("atecc: public key of slot %d: %w", slot, err)
+	}
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("atecc: slot %d does not hold an ecdsa key", slot)
+	}
+	return ecPub, nil
+}
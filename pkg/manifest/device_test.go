@@ -0,0 +1,91 @@
+package manifest
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"fmt"
+	"testing"
+
+	"github.com/northvolt/go-atecc/pkg/atecc"
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+type fakeDevice struct {
+	sn   []byte
+	keys map[uint8]*ecdsa.PrivateKey
+}
+
+func (d *fakeDevice) DeviceType() atecc.DeviceType { return atecc.DeviceATECC608 }
+func (d *fakeDevice) Variant() atecc.Variant       { return atecc.VariantTNG }
+
+func (d *fakeDevice) SerialNumber(context.Context) ([]byte, error) {
+	return d.sn, nil
+}
+
+func (d *fakeDevice) PublicKey(_ context.Context, slot uint8) (crypto.PublicKey, error) {
+	priv, ok := d.keys[slot]
+	if !ok {
+		return nil, fmt.Errorf("no key in slot %d", slot)
+	}
+	return priv.Public(), nil
+}
+
+func TestDevice(t *testing.T) {
+	ctx := context.Background()
+	d := &fakeDevice{
+		sn: []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0xee},
+		keys: map[uint8]*ecdsa.PrivateKey{
+			0: newTestKey(t),
+			2: newTestKey(t),
+		},
+	}
+
+	se, err := FromDevice(ctx, d, []int{0, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if se.UniqueID != "0123456789abcdefee" {
+		t.Errorf("got unique id %s", se.UniqueID)
+	}
+	if se.Model != "ATECC608" || se.PartNumber != "ATECC608-TNGTLS" {
+		t.Errorf("got model %s and part number %s", se.Model, se.PartNumber)
+	}
+	if len(se.PublicKeySet.Keys) != 2 || se.PublicKeySet.Keys[1].ID != "2" {
+		t.Errorf("got keys %+v", se.PublicKeySet.Keys)
+	}
+	if err := VerifyDevice(ctx, d, se); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromDevice(ctx, d, []int{1}); err == nil {
+		t.Error("expected error for slot without key")
+	}
+
+	// another key in the slot
+	d.keys[2] = newTestKey(t)
+	if err := VerifyDevice(ctx, d, se); err == nil {
+		t.Error("expected error for mismatching key")
+	}
+
+	// another device
+	d.sn = []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0xef}
+	if err := VerifyDevice(ctx, d, se); err == nil {
+		t.Error("expected error for mismatching serial number")
+	}
+}
+
+func TestPrivateKeySlots(t *testing.T) {
+	var c ateccconf.Config608
+	if err := ateccconf.UnmarshalPartial(ateccconf.Default608, ateccconf.PermanentOffset608, &c); err != nil {
+		t.Fatal(err)
+	}
+	for slot, kc := range c.KeyConfig {
+		c.KeyConfig[slot] = kc.WithPrivate(slot == 0 || slot == 3).WithKeyType(ateccconf.KeyTypePrivate)
+	}
+	c.KeyConfig[4] = c.KeyConfig[4].WithPrivate(true).WithKeyType(ateccconf.KeyTypeAES)
+
+	got := PrivateKeySlots(&c)
+	if fmt.Sprint(got) != "[0 3]" {
+		t.Errorf("got %v, want [0 3]", got)
+	}
+}
@@ -0,0 +1,206 @@
+package manifest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// ErrSignature is returned when the signature of a secure element is invalid.
+var ErrSignature = errors.New("atecc: invalid manifest signature")
+
+// SignedSecureElement is a secure element signed as a JWS.
+type SignedSecureElement struct {
+	// Payload is the base64url encoded SecureElement.
+	Payload string `json:"payload"`
+	// Protected is the base64url encoded ProtectedHeader.
+	Protected string `json:"protected"`
+	Header    Header `json:"header"`
+	// Signature is the base64url encoded ES256 signature.
+	Signature string `json:"signature"`
+}
+
+// Header is the unprotected header, which allows finding a device without
+// decoding the payload.
+type Header struct {
+	UniqueID string `json:"uniqueId"`
+}
+
+// ProtectedHeader is the JWS header covered by the signature.
+type ProtectedHeader struct {
+	Type      string `json:"typ"`
+	Algorithm string `json:"alg"`
+	// KeyID is the base64url encoded subject key ID of the signer certificate.
+	KeyID string `json:"kid,omitempty"`
+	// Thumbprint is the base64url encoded SHA-256 of the signer certificate.
+	Thumbprint string `json:"x5t#S256,omitempty"`
+}
+
+// Sign signs the secure element with the P-256 key of the signer.
+//
+// The signer certificate is optional. When given, it is referenced by the
+// protected header so that verifiers can find it.
+func Sign(se *SecureElement, signer crypto.Signer, cert *x509.Certificate) (*SignedSecureElement, error) {
+	pub, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok || pub.Curve != elliptic.P256() {
+		return nil, errors.New("atecc: manifest signer must be a P-256 key")
+	}
+	if cert != nil && !pub.Equal(cert.PublicKey) {
+		return nil, errors.New("atecc: signer certificate does not match the key")
+	}
+
+	header := ProtectedHeader{Type: "JWT", Algorithm: "ES256"}
+	if cert != nil {
+		thumbprint := sha256.Sum256(cert.Raw)
+		header.KeyID = base64.RawURLEncoding.EncodeToString(cert.SubjectKeyId)
+		header.Thumbprint = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	}
+
+	protected, err := encodeSegment(header)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := encodeSegment(se)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(protected + "." + payload))
+	der, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := rawSignature(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedSecureElement{
+		Payload:   payload,
+		Protected: protected,
+		Header:    Header{UniqueID: se.UniqueID},
+		Signature: base64.RawURLEncoding.EncodeToString(sig),
+	}, nil
+}
+
+// ProtectedHeader decodes the protected header without verifying it.
+func (s *SignedSecureElement) ProtectedHeader() (*ProtectedHeader, error) {
+	var header ProtectedHeader
+	if err := decodeSegment(s.Protected, &header); err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// SecureElement decodes the payload without verifying the signature.
+func (s *SignedSecureElement) SecureElement() (*SecureElement, error) {
+	var se SecureElement
+	if err := decodeSegment(s.Payload, &se); err != nil {
+		return nil, err
+	}
+	return &se, nil
+}
+
+// Verify verifies the signature using the signer certificate and returns the
+// secure element.
+//
+// It also verifies that the unprotected header matches the payload, and that
+// the certificates of each key are issued for it. The certificate chains are
+// not verified.
+func (s *SignedSecureElement) Verify(cert *x509.Certificate) (*SecureElement, error) {
+	header, err := s.ProtectedHeader()
+	if err != nil {
+		return nil, err
+	}
+	if header.Algorithm != "ES256" {
+		return nil, fmt.Errorf("atecc: unsupported manifest algorithm %q", header.Algorithm)
+	}
+	if header.Thumbprint != "" {
+		thumbprint := sha256.Sum256(cert.Raw)
+		if header.Thumbprint != base64.RawURLEncoding.EncodeToString(thumbprint[:]) {
+			return nil, errors.New("atecc: manifest is signed by another certificate")
+		}
+	}
+
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || pub.Curve != elliptic.P256() {
+		return nil, errors.New("atecc: manifest signer must be a P-256 key")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(s.Signature)
+	if err != nil || len(sig) != 64 {
+		return nil, ErrSignature
+	}
+	var (
+		r      = new(big.Int).SetBytes(sig[:32])
+		ss     = new(big.Int).SetBytes(sig[32:])
+		digest = sha256.Sum256([]byte(s.Protected + "." + s.Payload))
+	)
+	if !ecdsa.Verify(pub, digest[:], r, ss) {
+		return nil, ErrSignature
+	}
+
+	se, err := s.SecureElement()
+	if err != nil {
+		return nil, err
+	}
+	if se.UniqueID != s.Header.UniqueID {
+		return nil, fmt.Errorf("atecc: header unique id %s does not match %s", s.Header.UniqueID, se.UniqueID)
+	}
+	for _, k := range se.PublicKeySet.Keys {
+		if err := k.verifyCertificate(); err != nil {
+			return nil, err
+		}
+	}
+	return se, nil
+}
+
+func encodeSegment(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeSegment(s string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("atecc: invalid manifest segment: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("atecc: invalid manifest segment: %w", err)
+	}
+	return nil
+}
+
+// rawSignature converts an ASN.1 ECDSA signature to the fixed size r || s
+// used by JWS.
+func rawSignature(der []byte) ([]byte, error) {
+	var (
+		r, s  big.Int
+		inner cryptobyte.String
+	)
+	input := cryptobyte.String(der)
+	if !input.ReadASN1(&inner, asn1.SEQUENCE) ||
+		!input.Empty() ||
+		!inner.ReadASN1Integer(&r) ||
+		!inner.ReadASN1Integer(&s) ||
+		!inner.Empty() {
+		return nil, errors.New("atecc: invalid signature")
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return sig, nil
+}
@@ -0,0 +1,186 @@
+// Package manifest creates and verifies Trust Platform manifests.
+//
+// A manifest is a JSON array of signed secure elements. Each one is a JWS in
+// the flattened JSON serialization, signed with ES256, whose payload describes
+// a device: its unique ID, the public keys of its slots and their certificates.
+// Microchip issues manifests for pre-provisioned devices such as Trust&GO.
+//
+// See the TrustPlatform manifest file format for details.
+// https://github.com/MicrochipTech/cryptoauth_trustplatform_designsuite/blob/master/docs/TrustPlatform_manifest_file_format_2019-09-26_A.pdf
+package manifest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// Version is the version of the secure element format.
+const Version = 1
+
+// Manifest is a list of signed secure elements.
+type Manifest []SignedSecureElement
+
+// Decode reads a manifest.
+func Decode(r io.Reader) (Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("atecc: invalid manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Find returns the signed secure element with the unique ID.
+func (m Manifest) Find(uniqueID string) (*SignedSecureElement, bool) {
+	for i := range m {
+		if m[i].Header.UniqueID == uniqueID {
+			return &m[i], true
+		}
+	}
+	return nil, false
+}
+
+// SecureElement describes a device and its keys.
+type SecureElement struct {
+	Version      int           `json:"version"`
+	Model        string        `json:"model"`
+	PartNumber   string        `json:"partNumber,omitempty"`
+	Manufacturer *Organization `json:"manufacturer,omitempty"`
+	Provisioner  *Organization `json:"provisioner,omitempty"`
+	Distributor  *Organization `json:"distributor,omitempty"`
+	GroupID      string        `json:"groupId,omitempty"`
+	// ProvisioningTimestamp is when the device was provisioned.
+	ProvisioningTimestamp time.Time `json:"provisioningTimestamp"`
+	// UniqueID is the serial number of the device in lower case hex.
+	UniqueID     string `json:"uniqueId"`
+	PublicKeySet KeySet `json:"publicKeySet"`
+}
+
+// Organization is a party involved in producing the device.
+type Organization struct {
+	OrganizationName       string `json:"organizationName"`
+	OrganizationalUnitName string `json:"organizationalUnitName,omitempty"`
+}
+
+// KeySet is a JWK set of the public keys of a device.
+type KeySet struct {
+	Keys []Key `json:"keys"`
+}
+
+// Key is a public key as JWK, identified by the slot holding the private key.
+type Key struct {
+	// ID is the slot number.
+	ID      string `json:"kid"`
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv,omitempty"`
+	// X and Y are the base64url encoded coordinates.
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+	// Certificates is the certificate chain of the key, starting with the
+	// certificate of the key itself, in DER.
+	Certificates [][]byte `json:"x5c,omitempty"`
+}
+
+// UniqueID returns the unique ID of the device with the serial number.
+func UniqueID(sn []byte) string {
+	return hex.EncodeToString(sn)
+}
+
+// NewKey returns the JWK of the P-256 public key in the slot.
+func NewKey(slot int, pub *ecdsa.PublicKey, certs ...*x509.Certificate) (Key, error) {
+	if pub.Curve != elliptic.P256() {
+		return Key{}, errors.New("atecc: only P-256 keys are supported")
+	}
+	var x, y [32]byte
+	pub.X.FillBytes(x[:])
+	pub.Y.FillBytes(y[:])
+	k := Key{
+		ID:      strconv.Itoa(slot),
+		KeyType: "EC",
+		Curve:   "P-256",
+		X:       base64.RawURLEncoding.EncodeToString(x[:]),
+		Y:       base64.RawURLEncoding.EncodeToString(y[:]),
+	}
+	for _, cert := range certs {
+		k.Certificates = append(k.Certificates, cert.Raw)
+	}
+	return k, nil
+}
+
+// Slot returns the slot holding the private key.
+func (k Key) Slot() (int, error) {
+	slot, err := strconv.Atoi(k.ID)
+	if err != nil || slot < 0 || slot > 15 {
+		return 0, fmt.Errorf("atecc: invalid key id %q", k.ID)
+	}
+	return slot, nil
+}
+
+// PublicKey returns the public key of the JWK.
+func (k Key) PublicKey() (*ecdsa.PublicKey, error) {
+	if k.KeyType != "EC" || k.Curve != "P-256" {
+		return nil, fmt.Errorf("atecc: unsupported key type %s %s", k.KeyType, k.Curve)
+	}
+	x, err := decodeCoordinate(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeCoordinate(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	if !pub.Curve.IsOnCurve(x, y) {
+		return nil, errors.New("atecc: public key is not on the curve")
+	}
+	return pub, nil
+}
+
+func decodeCoordinate(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("atecc: invalid key coordinate %q", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// ParseCertificates parses the certificate chain of the key.
+func (k Key) ParseCertificates() ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, der := range k.Certificates {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// verifyCertificate checks that the first certificate is of the key.
+func (k Key) verifyCertificate() error {
+	if len(k.Certificates) == 0 {
+		return nil
+	}
+	pub, err := k.PublicKey()
+	if err != nil {
+		return err
+	}
+	certs, err := k.ParseCertificates()
+	if err != nil {
+		return fmt.Errorf("atecc: key %s: %w", k.ID, err)
+	}
+	if !pub.Equal(certs[0].PublicKey) {
+		return fmt.Errorf("atecc: key %s: certificate does not match the public key", k.ID)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package manifest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newTestCert(t *testing.T, pub *ecdsa.PublicKey, signer *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "manifest test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		SubjectKeyId: []byte{0x01, 0x02, 0x03, 0x04},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, pub, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+func TestSignVerify(t *testing.T) {
+	signer := newTestKey(t)
+	signerCert := newTestCert(t, &signer.PublicKey, signer)
+	deviceKey := newTestKey(t)
+	deviceCert := newTestCert(t, &deviceKey.PublicKey, signer)
+
+	k, err := NewKey(0, &deviceKey.PublicKey, deviceCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	se := &SecureElement{
+		Version:               Version,
+		Model:                 "ATECC608",
+		ProvisioningTimestamp: time.Date(2019, 1, 24, 16, 35, 23, 473e6, time.UTC),
+		UniqueID:              "0123456789abcdef01",
+		PublicKeySet:          KeySet{Keys: []Key{k}},
+	}
+	sse, err := Sign(se, signer, signerCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip through a manifest file
+	data, err := json.Marshal(Manifest{*sse})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, ok := m.Find(se.UniqueID)
+	if !ok {
+		t.Fatal("secure element not found")
+	}
+	got, err := found.Verify(signerCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UniqueID != se.UniqueID || !got.ProvisioningTimestamp.Equal(se.ProvisioningTimestamp) {
+		t.Errorf("got %+v, want %+v", got, se)
+	}
+	pub, err := got.PublicKeySet.Keys[0].PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(&deviceKey.PublicKey) {
+		t.Error("public key does not match")
+	}
+
+	// a payload signed by another key
+	other := newTestKey(t)
+	otherCert := newTestCert(t, &other.PublicKey, other)
+	if _, err := found.Verify(otherCert); err == nil {
+		t.Error("expected error for other signer certificate")
+	}
+	forged, err := Sign(se, other, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged.Protected = sse.Protected
+	if _, err := forged.Verify(signerCert); !errors.Is(err, ErrSignature) {
+		t.Errorf("got %v, want %v", err, ErrSignature)
+	}
+
+	// the unprotected header is not covered by the signature
+	moved := *sse
+	moved.Header.UniqueID = "0123456789abcdef02"
+	if _, err := moved.Verify(signerCert); err == nil {
+		t.Error("expected error for mismatching header")
+	}
+}
+
+func TestVerifyKeyCertificate(t *testing.T) {
+	signer := newTestKey(t)
+	deviceKey := newTestKey(t)
+	otherCert := newTestCert(t, &signer.PublicKey, signer)
+
+	// the certificate is of another key
+	k, err := NewKey(0, &deviceKey.PublicKey, otherCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	se := &SecureElement{Version: Version, UniqueID: "01", PublicKeySet: KeySet{Keys: []Key{k}}}
+	sse, err := Sign(se, signer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sse.Verify(otherCert); err == nil {
+		t.Error("expected error for certificate of another key")
+	}
+}
+
+func TestKey(t *testing.T) {
+	priv := newTestKey(t)
+	k, err := NewKey(15, &priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot, err := k.Slot(); err != nil || slot != 15 {
+		t.Errorf("got slot %d, %v", slot, err)
+	}
+
+	for _, invalid := range []Key{
+		{ID: "16", KeyType: "EC", Curve: "P-256", X: k.X, Y: k.Y},
+		{ID: "0", KeyType: "EC", Curve: "P-384", X: k.X, Y: k.Y},
+		{ID: "0", KeyType: "EC", Curve: "P-256", X: k.X, Y: k.X},
+		{ID: "0", KeyType: "EC", Curve: "P-256", X: k.X[1:], Y: k.Y},
+	} {
+		_, slotErr := invalid.Slot()
+		_, pubErr := invalid.PublicKey()
+		if slotErr == nil && pubErr == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
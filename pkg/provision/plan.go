@@ -0,0 +1,267 @@
+// Package provision provisions ATECC608 devices according to a plan.
+//
+// A plan declares the configuration, the contents of each slot and of the OTP
+// zone, and which zones to lock. Running a plan inspects the lock state of the
+// device first, which makes it safe to run again to resume after a partial
+// failure. Every run produces a record of the steps taken, for auditing.
+package provision
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+	"github.com/northvolt/go-atecc/pkg/manifest"
+)
+
+// blockSize is the size of the writes allowed before the data zone is locked.
+const blockSize = 32
+
+// Plan declares how to provision a device.
+//
+// A plan is decoded from JSON, e.g.
+//
+//	{
+//	  "template": "pkcs11",
+//	  "i2c_address": "0x35",
+//	  "slots": [
+//	    {"slot": 0, "generate_key": true, "lock": true},
+//	    {"slot": 8, "certificate": "device.pem"}
+//	  ],
+//	  "otp": "0102",
+//	  "lock": {"config": true, "data": true}
+//	}
+type Plan struct {
+	// Template is the name of an embedded configuration template.
+	Template string `json:"template,omitempty"`
+	// Config is the configuration to use instead of a template.
+	Config *ateccconf.Config608 `json:"config,omitempty"`
+	// I2CAddress is the 7-bit I²C address, e.g. "0x35", replacing the one of
+	// the configuration.
+	I2CAddress string `json:"i2c_address,omitempty"`
+	// Slots are the actions for each slot, executed in order.
+	Slots []SlotPlan `json:"slots,omitempty"`
+	// OTP is the hex encoded contents of the OTP zone, padded with zeros.
+	OTP  string `json:"otp,omitempty"`
+	Lock Locks  `json:"lock"`
+
+	config *ateccconf.Config608
+	otp    []byte
+	digest string
+}
+
+// SlotPlan declares the contents of a slot.
+//
+// At most one of GenerateKey, Data, PrivateKey and Certificate may be set.
+// Data and certificates are written from the start of the slot and padded with
+// zeros to a multiple of 32 bytes, as required before the data zone is locked.
+type SlotPlan struct {
+	Slot int `json:"slot"`
+	// GenerateKey generates a private key in the slot.
+	GenerateKey bool `json:"generate_key,omitempty"`
+	// Data is the hex encoded data of the slot.
+	Data string `json:"data,omitempty"`
+	// PrivateKey is the path of a PEM encoded P-256 private key, written in
+	// the clear using PrivWrite.
+	PrivateKey string `json:"private_key,omitempty"`
+	// Certificate is the path of a PEM or DER encoded certificate, written to
+	// the slot as DER.
+	Certificate string `json:"certificate,omitempty"`
+	// Lock locks the slot once the data zone is locked.
+	Lock bool `json:"lock,omitempty"`
+
+	data []byte
+	key  *ecdsa.PrivateKey
+}
+
+// Locks declares the zones to lock.
+//
+// The data zone can only be locked after the config zone, and slots only
+// after the data zone.
+type Locks struct {
+	Config bool `json:"config"`
+	Data   bool `json:"data"`
+}
+
+// Load reads and validates the plan with the name.
+//
+// Files referenced by the plan are read relative to the directory of the plan.
+func Load(fsys fs.FS, name string) (*Plan, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Plan
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("atecc: invalid plan: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	p.digest = hex.EncodeToString(digest[:])
+
+	if err := p.resolve(fsys, path.Dir(name)); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Digest returns the hex encoded SHA-256 of the plan file.
+func (p *Plan) Digest() string {
+	return p.digest
+}
+
+// resolve validates the plan and reads the files it references.
+func (p *Plan) resolve(fsys fs.FS, dir string) error {
+	switch {
+	case p.Template != "" && p.Config != nil:
+		return errors.New("atecc: plan has both a template and a config")
+	case p.Template != "":
+		t, err := ateccconf.Template(p.Template)
+		if err != nil {
+			return err
+		}
+		if p.config, err = t.Config(); err != nil {
+			return err
+		}
+	case p.Config != nil:
+		conf := *p.Config
+		p.config = &conf
+	default:
+		return errors.New("atecc: plan has neither a template nor a config")
+	}
+
+	if p.I2CAddress != "" {
+		addr, err := strconv.ParseUint(p.I2CAddress, 0, 7)
+		if err != nil {
+			return fmt.Errorf("atecc: invalid i2c address %q", p.I2CAddress)
+		}
+		p.config.I2CAddress = byte(addr << 1)
+	}
+
+	otp, err := hex.DecodeString(p.OTP)
+	if err != nil {
+		return fmt.Errorf("atecc: invalid otp: %w", err)
+	}
+	if len(otp) > ateccconf.OTPSize {
+		return fmt.Errorf("atecc: otp exceeds %d bytes", ateccconf.OTPSize)
+	}
+	p.otp = make([]byte, ateccconf.OTPSize)
+	copy(p.otp, otp)
+
+	if p.Lock.Data && !p.Lock.Config {
+		return errors.New("atecc: locking the data zone requires locking the config zone")
+	}
+
+	seen := make(map[int]bool)
+	for i := range p.Slots {
+		sp := &p.Slots[i]
+		if sp.Slot < 0 || sp.Slot > 15 {
+			return fmt.Errorf("atecc: invalid slot %d", sp.Slot)
+		}
+		if seen[sp.Slot] {
+			return fmt.Errorf("atecc: slot %d is planned twice", sp.Slot)
+		}
+		seen[sp.Slot] = true
+		if sp.Lock && !p.Lock.Data {
+			return fmt.Errorf("atecc: locking slot %d requires locking the data zone", sp.Slot)
+		}
+		if err := sp.resolve(fsys, dir); err != nil {
+			return fmt.Errorf("atecc: slot %d: %w", sp.Slot, err)
+		}
+	}
+	return nil
+}
+
+func (sp *SlotPlan) resolve(fsys fs.FS, dir string) error {
+	var actions int
+	for _, set := range []bool{sp.GenerateKey, sp.Data != "", sp.PrivateKey != "", sp.Certificate != ""} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		return errors.New("only one of generate_key, data, private_key and certificate may be set")
+	}
+
+	var err error
+	switch {
+	case sp.Data != "":
+		if sp.data, err = hex.DecodeString(sp.Data); err != nil {
+			return fmt.Errorf("invalid data: %w", err)
+		}
+	case sp.Certificate != "":
+		if sp.data, err = readCertificate(fsys, path.Join(dir, sp.Certificate)); err != nil {
+			return err
+		}
+	case sp.PrivateKey != "":
+		if sp.key, err = readPrivateKey(fsys, path.Join(dir, sp.PrivateKey)); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	// pad to whole blocks, except for a last block past the end of the slot
+	size := slotSize(sp.Slot)
+	if len(sp.data) > size {
+		return fmt.Errorf("data exceeds %d bytes", size)
+	}
+	if n := len(sp.data) % blockSize; n != 0 {
+		pad := blockSize - n
+		if len(sp.data)+pad > size {
+			pad = size - len(sp.data)
+		}
+		sp.data = append(sp.data, make([]byte, pad)...)
+	}
+	return nil
+}
+
+// readCertificate returns the DER of the first PEM, or the DER, encoded
+// certificate.
+func readCertificate(fsys fs.FS, name string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := manifest.ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, name)
+	}
+	return certs[0].Raw, nil
+}
+
+// readPrivateKey reads a PEM encoded PKCS #8 or SEC 1 P-256 private key.
+func readPrivateKey(fsys fs.FS, name string) (*ecdsa.PrivateKey, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	key, err := manifest.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, name)
+	}
+	return key, nil
+}
+
+// slotSize returns the size of the ATECC608 data slot.
+func slotSize(slot int) int {
+	switch {
+	case slot < 8:
+		return 36
+	case slot == 8:
+		return 416
+	default:
+		return 72
+	}
+}
@@ -0,0 +1,121 @@
+package provision
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+// newTestFS returns a plan with a certificate and private key.
+func newTestFS(t *testing.T) (fstest.MapFS, []byte, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "provision test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{
+		"plans/plan.json": {Data: []byte(`{
+			"template": "pkcs11",
+			"i2c_address": "0x35",
+			"slots": [
+				{"slot": 0, "generate_key": true, "lock": true},
+				{"slot": 2, "private_key": "keys/device.key"},
+				{"slot": 8, "certificate": "device.pem", "lock": true},
+				{"slot": 9, "data": "0102"}
+			],
+			"otp": "aa",
+			"lock": {"config": true, "data": true}
+		}`)},
+		"plans/device.pem":      {Data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})},
+		"plans/keys/device.key": {Data: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})},
+		"config.json":           {},
+	}
+	return fsys, cert, key
+}
+
+func TestLoad(t *testing.T) {
+	fsys, cert, key := newTestFS(t)
+	p, err := Load(fsys, "plans/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.config.I2CAddress != 0x6a {
+		t.Errorf("got i2c address %#x", p.config.I2CAddress)
+	}
+	if len(p.otp) != ateccconf.OTPSize || p.otp[0] != 0xaa {
+		t.Errorf("got otp %x", p.otp)
+	}
+	if !p.Slots[1].key.Equal(key) {
+		t.Error("private key not loaded")
+	}
+	if got := p.Slots[2].data; len(got)%blockSize != 0 || string(got[:len(cert)]) != string(cert) {
+		t.Error("certificate not loaded")
+	}
+	if got := p.Slots[3].data; len(got) != blockSize || got[0] != 0x01 || got[1] != 0x02 {
+		t.Errorf("got data %x", got)
+	}
+
+	// a last partial block is padded up to the end of the slot
+	full, err := Load(fstest.MapFS{"plan.json": {Data: []byte(`{"template": "pkcs11", "slots": [{"slot": 9, "data": "` + strings.Repeat("01", 72) + `"}]}`)}}, "plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := full.Slots[0].data; len(got) != 72 {
+		t.Errorf("got %d bytes for a full slot, want 72", len(got))
+	}
+	if len(p.Digest()) != 64 {
+		t.Errorf("got digest %s", p.Digest())
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		plan string
+		err  string
+	}{
+		{`{}`, "neither"},
+		{`{"template": "pkcs11", "config": {}}`, "both"},
+		{`{"template": "unknown"}`, "unknown template"},
+		{`{"template": "pkcs11", "unknown": true}`, "unknown field"},
+		{`{"template": "pkcs11", "i2c_address": "0x80"}`, "i2c address"},
+		{`{"template": "pkcs11", "otp": "zz"}`, "otp"},
+		{`{"template": "pkcs11", "otp": "` + strings.Repeat("00", 65) + `"}`, "otp exceeds"},
+		{`{"template": "pkcs11", "lock": {"data": true}}`, "config zone"},
+		{`{"template": "pkcs11", "slots": [{"slot": 16}]}`, "invalid slot"},
+		{`{"template": "pkcs11", "slots": [{"slot": 1}, {"slot": 1}]}`, "twice"},
+		{`{"template": "pkcs11", "slots": [{"slot": 1, "lock": true}]}`, "data zone"},
+		{`{"template": "pkcs11", "slots": [{"slot": 1, "generate_key": true, "data": "00"}]}`, "only one"},
+		{`{"template": "pkcs11", "slots": [{"slot": 1, "data": "` + strings.Repeat("00", 37) + `"}]}`, "exceeds 36"},
+		{`{"template": "pkcs11", "slots": [{"slot": 1, "certificate": "missing.pem"}]}`, "missing.pem"},
+	} {
+		fsys := fstest.MapFS{"plan.json": {Data: []byte(tc.plan)}}
+		if _, err := Load(fsys, "plan.json"); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want error containing %q", tc.plan, err, tc.err)
+		}
+	}
+}
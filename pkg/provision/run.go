@@ -0,0 +1,380 @@
+package provision
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/northvolt/go-atecc/pkg/atecc"
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+// Device is the part of atecc.Dev used to run a plan.
+type Device interface {
+	DeviceType() atecc.DeviceType
+	SerialNumber(ctx context.Context) ([]byte, error)
+	ReadConfigZone(ctx context.Context) ([]byte, error)
+	WriteConfigZone(ctx context.Context, data []byte) error
+	WriteBytesZone(ctx context.Context, zone atecc.Zone, slot uint16, offset uint8, data []byte) error
+	GenerateKey(ctx context.Context, slot uint8) (crypto.PublicKey, error)
+	PublicKey(ctx context.Context, slot uint8) (crypto.PublicKey, error)
+	PrivWrite(ctx context.Context, slot uint8, priv *ecdsa.PrivateKey) error
+	LockConfigZoneCRC(ctx context.Context, expected []byte) error
+	LockDataZone(ctx context.Context) error
+	LockDataSlot(ctx context.Context, slot uint8) error
+	LockDataSlotCRC(ctx context.Context, slot uint8, expected []byte) error
+}
+
+var _ Device = (*atecc.Dev)(nil)
+
+// Action is a step of provisioning.
+type Action string
+
+const (
+	ActionWriteConfig      = Action("write-config")
+	ActionLockConfig       = Action("lock-config")
+	ActionGenerateKey      = Action("generate-key")
+	ActionWriteData        = Action("write-data")
+	ActionPrivWrite        = Action("priv-write")
+	ActionWriteCertificate = Action("write-certificate")
+	ActionWriteOTP         = Action("write-otp")
+	ActionLockData         = Action("lock-data")
+	ActionLockSlot         = Action("lock-slot")
+)
+
+// Status is the outcome of a step.
+type Status string
+
+const (
+	// StatusDone is a step executed by the run.
+	StatusDone = Status("done")
+	// StatusSkipped is a step found to be done by a previous run.
+	StatusSkipped = Status("skipped")
+	// StatusPending is a step not executed, e.g. by a dry run or because the
+	// plan does not lock a zone it depends on.
+	StatusPending = Status("pending")
+	// StatusFailed is a step which failed, ending the run.
+	StatusFailed = Status("failed")
+)
+
+// Step is a step of a run.
+type Step struct {
+	Action Action `json:"action"`
+	Slot   *int   `json:"slot,omitempty"`
+	Status Status `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	// PublicKey is the hex encoded X and Y of the key in the slot.
+	PublicKey string    `json:"public_key,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Record is the audit record of a run.
+type Record struct {
+	SerialNumber string `json:"serial_number"`
+	DeviceType   string `json:"device_type"`
+	// PlanDigest is the hex encoded SHA-256 of the plan file.
+	PlanDigest string    `json:"plan_digest"`
+	DryRun     bool      `json:"dry_run"`
+	Started    time.Time `json:"started"`
+	Finished   time.Time `json:"finished"`
+	Steps      []Step    `json:"steps"`
+	// Complete indicates that all steps are done or skipped.
+	Complete bool   `json:"complete"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Options controls a run.
+type Options struct {
+	// DryRun reports the steps which would be executed without writing to
+	// the device.
+	DryRun bool
+}
+
+// Run provisions the device according to the plan.
+//
+// The lock state of the device decides which steps are executed. Steps of a
+// locked zone are skipped, after verifying that a locked config zone matches
+// the plan. Steps of an unlocked zone are executed again, which means keys
+// are generated again until the data zone is locked.
+//
+// The record is returned even if the run fails.
+func Run(ctx context.Context, d Device, p *Plan, opts Options) (*Record, error) {
+	r := &runner{
+		d:    d,
+		plan: p,
+		opts: opts,
+		rec: &Record{
+			DeviceType: d.DeviceType().String(),
+			PlanDigest: p.Digest(),
+			DryRun:     opts.DryRun,
+			Started:    time.Now().UTC(),
+			Steps:      []Step{},
+		},
+	}
+
+	err := r.run(ctx)
+	rec := r.rec
+	rec.Finished = time.Now().UTC()
+	rec.Complete = err == nil
+	for _, s := range rec.Steps {
+		rec.Complete = rec.Complete && (s.Status == StatusDone || s.Status == StatusSkipped)
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	return rec, err
+}
+
+type runner struct {
+	d    Device
+	plan *Plan
+	opts Options
+	rec  *Record
+}
+
+func (r *runner) run(ctx context.Context) error {
+	if dt := r.d.DeviceType(); dt != atecc.DeviceATECC608 {
+		return fmt.Errorf("%w: provisioning %s", atecc.ErrUnsupportedCommand, dt)
+	}
+
+	sn, err := r.d.SerialNumber(ctx)
+	if err != nil {
+		return err
+	}
+	r.rec.SerialNumber = hex.EncodeToString(sn)
+
+	config, err := r.d.ReadConfigZone(ctx)
+	if err != nil {
+		return err
+	}
+	cur, err := ateccconf.UnmarshalConfig608(config)
+	if err != nil {
+		return err
+	}
+	var (
+		configLocked = cur.LockConfig.IsLocked()
+		dataLocked   = cur.LockValue.IsLocked()
+	)
+
+	if configLocked {
+		if err := r.checkConfig(cur); err != nil {
+			return err
+		}
+		r.skip(ActionWriteConfig, nil, "config zone locked")
+		r.skip(ActionLockConfig, nil, "config zone locked")
+	} else {
+		if err := r.configZone(ctx); err != nil {
+			return err
+		}
+		configLocked = r.plan.Lock.Config && !r.opts.DryRun
+	}
+
+	// the data zone can only be written once the config zone is locked
+	var blocked string
+	if !configLocked {
+		blocked = "config zone unlocked"
+	}
+	for _, sp := range r.plan.Slots {
+		if err := r.slot(ctx, sp, dataLocked, blocked); err != nil {
+			return err
+		}
+	}
+
+	if dataLocked {
+		r.skip(ActionWriteOTP, nil, "data zone locked")
+		r.skip(ActionLockData, nil, "data zone locked")
+	} else {
+		err := r.step(ActionWriteOTP, nil, blocked, func(*Step) error {
+			return r.d.WriteBytesZone(ctx, atecc.ZoneOTP, 0, 0, r.plan.otp)
+		})
+		if err != nil {
+			return err
+		}
+
+		if !r.plan.Lock.Data {
+			r.pending(ActionLockData, nil, "not requested")
+		} else if err := r.step(ActionLockData, nil, blocked, func(*Step) error {
+			return r.d.LockDataZone(ctx)
+		}); err != nil {
+			return err
+		}
+		dataLocked = r.plan.Lock.Data && blocked == "" && !r.opts.DryRun
+	}
+
+	blocked = ""
+	if !dataLocked {
+		blocked = "data zone unlocked"
+	}
+	for _, sp := range r.plan.Slots {
+		if !sp.Lock {
+			continue
+		}
+		slot := sp.Slot
+		if cur.SlotLocked.IsLocked(slot) {
+			r.skip(ActionLockSlot, &slot, "slot locked")
+			continue
+		}
+		err := r.step(ActionLockSlot, &slot, blocked, func(*Step) error {
+			// the contents are only known when written in full
+			if sp.data != nil && len(sp.data) == slotSize(slot) {
+				return r.d.LockDataSlotCRC(ctx, uint8(slot), sp.data)
+			}
+			return r.d.LockDataSlot(ctx, uint8(slot))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkConfig verifies that the configuration matches the plan.
+func (r *runner) checkConfig(conf *ateccconf.Config608) error {
+	diffs, err := ateccconf.Diff(conf, r.plan.config, ateccconf.DiffOptions{
+		IgnoreFactory: true,
+		IgnoreLock:    true,
+	})
+	if err != nil {
+		return err
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("atecc: config zone does not match the plan, %d fields differ: %s", len(diffs), diffs[0])
+	}
+	return nil
+}
+
+// configZone writes, verifies and locks the config zone.
+func (r *runner) configZone(ctx context.Context) error {
+	want, err := ateccconf.Marshal(r.plan.config)
+	if err != nil {
+		return err
+	}
+
+	var got []byte
+	err = r.step(ActionWriteConfig, nil, "", func(*Step) error {
+		if err := r.d.WriteConfigZone(ctx, want); err != nil {
+			return err
+		}
+		if got, err = r.d.ReadConfigZone(ctx); err != nil {
+			return err
+		}
+		conf, err := ateccconf.UnmarshalConfig608(got)
+		if err != nil {
+			return err
+		}
+		return r.checkConfig(conf)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !r.plan.Lock.Config {
+		r.pending(ActionLockConfig, nil, "not requested")
+		return nil
+	}
+	return r.step(ActionLockConfig, nil, "", func(*Step) error {
+		// the factory and lock bytes are not written
+		expected := append([]byte(nil), want...)
+		copy(expected, got[:ateccconf.PermanentOffset608])
+		copy(expected[86:88], got[86:88])
+		return r.d.LockConfigZoneCRC(ctx, expected)
+	})
+}
+
+// slot writes the contents of the slot.
+func (r *runner) slot(ctx context.Context, sp SlotPlan, dataLocked bool, blocked string) error {
+	slot := sp.Slot
+	switch {
+	case sp.GenerateKey:
+		if dataLocked {
+			pub, err := r.d.PublicKey(ctx, uint8(slot))
+			if err != nil {
+				return err
+			}
+			r.skip(ActionGenerateKey, &slot, "data zone locked").PublicKey = encodePublicKey(pub)
+			return nil
+		}
+		return r.step(ActionGenerateKey, &slot, blocked, func(s *Step) error {
+			pub, err := r.d.GenerateKey(ctx, uint8(slot))
+			if err != nil {
+				return err
+			}
+			s.PublicKey = encodePublicKey(pub)
+			return nil
+		})
+	case sp.key != nil:
+		if dataLocked {
+			r.skip(ActionPrivWrite, &slot, "data zone locked")
+			return nil
+		}
+		return r.step(ActionPrivWrite, &slot, blocked, func(s *Step) error {
+			s.PublicKey = encodePublicKey(&sp.key.PublicKey)
+			return r.d.PrivWrite(ctx, uint8(slot), sp.key)
+		})
+	case sp.data != nil:
+		action := ActionWriteData
+		if sp.Certificate != "" {
+			action = ActionWriteCertificate
+		}
+		if dataLocked {
+			r.skip(action, &slot, "data zone locked")
+			return nil
+		}
+		return r.step(action, &slot, blocked, func(*Step) error {
+			return r.d.WriteBytesZone(ctx, atecc.ZoneData, uint16(slot), 0, sp.data)
+		})
+	}
+	return nil
+}
+
+// step executes the step, unless blocked or a dry run.
+func (r *runner) step(action Action, slot *int, blocked string, fn func(*Step) error) error {
+	switch {
+	case blocked != "":
+		r.pending(action, slot, blocked)
+		return nil
+	case r.opts.DryRun:
+		r.pending(action, slot, "dry run")
+		return nil
+	}
+
+	s := Step{Action: action, Slot: slot, Status: StatusDone}
+	err := fn(&s)
+	if err != nil {
+		s.Status = StatusFailed
+		s.Reason = err.Error()
+	}
+	r.add(s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	return nil
+}
+
+func (r *runner) skip(action Action, slot *int, reason string) *Step {
+	return r.add(Step{Action: action, Slot: slot, Status: StatusSkipped, Reason: reason})
+}
+
+func (r *runner) pending(action Action, slot *int, reason string) {
+	r.add(Step{Action: action, Slot: slot, Status: StatusPending, Reason: reason})
+}
+
+func (r *runner) add(s Step) *Step {
+	s.Time = time.Now().UTC()
+	r.rec.Steps = append(r.rec.Steps, s)
+	return &r.rec.Steps[len(r.rec.Steps)-1]
+}
+
+func encodePublicKey(pub crypto.PublicKey) string {
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return ""
+	}
+	var b [64]byte
+	ecPub.X.FillBytes(b[:32])
+	ecPub.Y.FillBytes(b[32:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,330 @@
+package provision
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/northvolt/go-atecc/pkg/atecc"
+	"github.com/northvolt/go-atecc/pkg/ateccconf"
+)
+
+var errFake = errors.New("fake: failure")
+
+// fakeDevice emulates the memory and locks of an ATECC608.
+type fakeDevice struct {
+	config [ateccconf.ConfigSize608]byte
+	otp    []byte
+	data   [16][]byte
+	keys   [16]*ecdsa.PrivateKey
+
+	// fail is the method to fail on the next call.
+	fail string
+}
+
+func newFakeDevice() *fakeDevice {
+	d := &fakeDevice{otp: make([]byte, ateccconf.OTPSize)}
+	copy(d.config[:], []byte{0x01, 0x23, 0x45, 0x67, 0x00, 0x00, 0x60, 0x03, 0x89, 0xab, 0xcd, 0xef, 0xee})
+	copy(d.config[ateccconf.PermanentOffset608:], ateccconf.Default608)
+	for i := range d.data {
+		d.data[i] = make([]byte, slotSize(i))
+	}
+	return d
+}
+
+func (d *fakeDevice) configLocked() bool { return d.config[87] != 0x55 }
+func (d *fakeDevice) dataLocked() bool   { return d.config[86] != 0x55 }
+
+func (d *fakeDevice) call(name string) error {
+	if d.fail == name {
+		d.fail = ""
+		return errFake
+	}
+	return nil
+}
+
+func (d *fakeDevice) DeviceType() atecc.DeviceType { return atecc.DeviceATECC608 }
+
+func (d *fakeDevice) SerialNumber(context.Context) ([]byte, error) {
+	return append(append([]byte(nil), d.config[0:4]...), d.config[8:13]...), nil
+}
+
+func (d *fakeDevice) ReadConfigZone(context.Context) ([]byte, error) {
+	return append([]byte(nil), d.config[:]...), d.call("ReadConfigZone")
+}
+
+func (d *fakeDevice) WriteConfigZone(_ context.Context, data []byte) error {
+	if err := d.call("WriteConfigZone"); err != nil {
+		return err
+	}
+	if d.configLocked() {
+		return atecc.ErrExecution
+	}
+	copy(d.config[16:86], data[16:86])
+	copy(d.config[88:], data[88:])
+	return nil
+}
+
+func (d *fakeDevice) WriteBytesZone(_ context.Context, zone atecc.Zone, slot uint16, offset uint8, data []byte) error {
+	if err := d.call("WriteBytesZone"); err != nil {
+		return err
+	}
+	if !d.configLocked() || d.dataLocked() || len(data)%32 != 0 {
+		return atecc.ErrExecution
+	}
+	switch zone {
+	case atecc.ZoneOTP:
+		copy(d.otp[offset:], data)
+	case atecc.ZoneData:
+		copy(d.data[slot][offset:], data)
+	default:
+		return atecc.ErrExecution
+	}
+	return nil
+}
+
+func (d *fakeDevice) GenerateKey(_ context.Context, slot uint8) (crypto.PublicKey, error) {
+	if err := d.call("GenerateKey"); err != nil {
+		return nil, err
+	}
+	if !d.configLocked() || d.dataLocked() {
+		return nil, atecc.ErrExecution
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	d.keys[slot] = priv
+	return priv.Public(), nil
+}
+
+func (d *fakeDevice) PublicKey(_ context.Context, slot uint8) (crypto.PublicKey, error) {
+	if d.keys[slot] == nil {
+		return nil, atecc.ErrExecution
+	}
+	return d.keys[slot].Public(), nil
+}
+
+func (d *fakeDevice) PrivWrite(_ context.Context, slot uint8, priv *ecdsa.PrivateKey) error {
+	if err := d.call("PrivWrite"); err != nil {
+		return err
+	}
+	if !d.configLocked() || d.dataLocked() {
+		return atecc.ErrExecution
+	}
+	d.keys[slot] = priv
+	return nil
+}
+
+func (d *fakeDevice) LockConfigZoneCRC(_ context.Context, expected []byte) error {
+	if err := d.call("LockConfigZoneCRC"); err != nil {
+		return err
+	}
+	if d.configLocked() || ateccconf.LockCRC(expected) != ateccconf.LockCRC(d.config[:]) {
+		return atecc.ErrExecution
+	}
+	d.config[87] = 0x00
+	return nil
+}
+
+func (d *fakeDevice) LockDataZone(context.Context) error {
+	if err := d.call("LockDataZone"); err != nil {
+		return err
+	}
+	if !d.configLocked() || d.dataLocked() {
+		return atecc.ErrExecution
+	}
+	d.config[86] = 0x00
+	return nil
+}
+
+func (d *fakeDevice) LockDataSlot(_ context.Context, slot uint8) error {
+	if err := d.call("LockDataSlot"); err != nil {
+		return err
+	}
+	if !d.dataLocked() {
+		return atecc.ErrExecution
+	}
+	d.config[88+slot/8] &^= 1 << (slot % 8)
+	return nil
+}
+
+func (d *fakeDevice) LockDataSlotCRC(ctx context.Context, slot uint8, expected []byte) error {
+	if !bytes.Equal(expected, d.data[slot]) {
+		return atecc.ErrExecution
+	}
+	return d.LockDataSlot(ctx, slot)
+}
+
+// steps returns the action, slot and status of the steps.
+func steps(rec *Record) []string {
+	var s []string
+	for _, step := range rec.Steps {
+		if step.Slot != nil {
+			s = append(s, fmt.Sprintf("%s[%d]:%s", step.Action, *step.Slot, step.Status))
+		} else {
+			s = append(s, fmt.Sprintf("%s:%s", step.Action, step.Status))
+		}
+	}
+	return s
+}
+
+func checkSteps(t *testing.T, rec *Record, want ...string) {
+	t.Helper()
+	if got := steps(rec); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got steps\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	fsys, cert, key := newTestFS(t)
+	p, err := Load(fsys, "plans/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newFakeDevice()
+
+	// nothing is written by a dry run
+	before := d.config
+	rec, err := Run(ctx, d, p, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Complete || before != d.config {
+		t.Error("dry run changed the device")
+	}
+	checkSteps(t, rec,
+		"write-config:pending", "lock-config:pending",
+		"generate-key[0]:pending", "priv-write[2]:pending", "write-certificate[8]:pending", "write-data[9]:pending",
+		"write-otp:pending", "lock-data:pending",
+		"lock-slot[0]:pending", "lock-slot[8]:pending",
+	)
+
+	rec, err = Run(ctx, d, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Complete || rec.SerialNumber != "0123456789abcdefee" || rec.PlanDigest != p.Digest() {
+		t.Errorf("got record %+v", rec)
+	}
+	checkSteps(t, rec,
+		"write-config:done", "lock-config:done",
+		"generate-key[0]:done", "priv-write[2]:done", "write-certificate[8]:done", "write-data[9]:done",
+		"write-otp:done", "lock-data:done",
+		"lock-slot[0]:done", "lock-slot[8]:done",
+	)
+	if d.config[16] != 0x35<<1 {
+		t.Errorf("got i2c address %#x", d.config[16])
+	}
+	if !bytes.HasPrefix(d.data[8], cert) || !bytes.HasPrefix(d.data[9], []byte{0x01, 0x02, 0x00}) {
+		t.Error("slot data not written")
+	}
+	if !d.keys[2].Equal(key) || d.otp[0] != 0xaa {
+		t.Error("private key or otp not written")
+	}
+	pub := rec.Steps[2].PublicKey
+
+	// running again finds everything done
+	rec, err = Run(ctx, d, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Complete {
+		t.Error("expected complete record")
+	}
+	checkSteps(t, rec,
+		"write-config:skipped", "lock-config:skipped",
+		"generate-key[0]:skipped", "priv-write[2]:skipped", "write-certificate[8]:skipped", "write-data[9]:skipped",
+		"write-otp:skipped", "lock-data:skipped",
+		"lock-slot[0]:skipped", "lock-slot[8]:skipped",
+	)
+	if rec.Steps[2].PublicKey != pub {
+		t.Errorf("got public key %s, want %s", rec.Steps[2].PublicKey, pub)
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	ctx := context.Background()
+	fsys, _, _ := newTestFS(t)
+	p, err := Load(fsys, "plans/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newFakeDevice()
+
+	d.fail = "LockDataZone"
+	rec, err := Run(ctx, d, p, Options{})
+	if !errors.Is(err, errFake) {
+		t.Fatalf("got %v, want %v", err, errFake)
+	}
+	if rec.Complete || rec.Error == "" {
+		t.Errorf("got record %+v", rec)
+	}
+	checkSteps(t, rec,
+		"write-config:done", "lock-config:done",
+		"generate-key[0]:done", "priv-write[2]:done", "write-certificate[8]:done", "write-data[9]:done",
+		"write-otp:done", "lock-data:failed",
+	)
+
+	// the data zone is still unlocked and is written again
+	rec, err = Run(ctx, d, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSteps(t, rec,
+		"write-config:skipped", "lock-config:skipped",
+		"generate-key[0]:done", "priv-write[2]:done", "write-certificate[8]:done", "write-data[9]:done",
+		"write-otp:done", "lock-data:done",
+		"lock-slot[0]:done", "lock-slot[8]:done",
+	)
+}
+
+func TestRunPartialLocks(t *testing.T) {
+	ctx := context.Background()
+	fsys, _, _ := newTestFS(t)
+	fsys["config.json"].Data = []byte(`{"template": "pkcs11", "slots": [{"slot": 0, "generate_key": true}]}`)
+	p, err := Load(fsys, "config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newFakeDevice()
+
+	// data zone steps are pending until the config zone is locked
+	rec, err := Run(ctx, d, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Complete {
+		t.Error("expected incomplete record")
+	}
+	checkSteps(t, rec,
+		"write-config:done", "lock-config:pending", "generate-key[0]:pending", "write-otp:pending", "lock-data:pending",
+	)
+}
+
+func TestRunConfigMismatch(t *testing.T) {
+	ctx := context.Background()
+	fsys, _, _ := newTestFS(t)
+	p, err := Load(fsys, "plans/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newFakeDevice()
+	d.config[87] = 0x00
+
+	// the default config differs from the template of the plan
+	rec, err := Run(ctx, d, p, Options{})
+	if err == nil {
+		t.Fatal("expected error for locked config zone with another config")
+	}
+	if len(rec.Steps) != 0 || rec.Error == "" {
+		t.Errorf("got record %+v", rec)
+	}
+}